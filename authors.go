@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- AuthorsScreen ---
+
+// AuthorsScreen lists everyone who wrote notes, as found with author_dirs,
+// and shows what each of them worked on and when.
+type AuthorsScreen struct {
+	Root       *tview.Grid
+	innerPages *tview.Pages
+	filter     *tview.InputField
+	list       *tview.List
+	detail     *tview.TextView
+	split      *splitView
+	data       *TrailData
+	app        *tview.Application
+	authors    []string // list items, in display order
+	shown      string   // author on the detail page
+}
+
+func newAuthorsScreen(data *TrailData, app *tview.Application) *AuthorsScreen {
+	as := &AuthorsScreen{data: data, app: app}
+
+	as.filter = tview.NewInputField().
+		SetLabel("Filter Authors: ").
+		SetChangedFunc(func(text string) {
+			as.populateAuthors(text)
+		})
+	as.filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			app.SetFocus(as.list)
+		}
+	})
+
+	as.list = tview.NewList()
+	as.detail = tview.NewTextView().SetScrollable(true)
+
+	as.innerPages = tview.NewPages()
+	as.innerPages.AddPage("list", as.list, true, true)
+	as.innerPages.AddPage("detail", as.detail, true, false)
+	as.split = newSplitView(as.innerPages)
+
+	as.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		as.preview(index)
+	})
+
+	as.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
+	as.Root.AddItem(as.filter, 0, 0, 1, 1, 0, 0, false)
+	as.Root.AddItem(as.split, 1, 0, 1, 1, 0, 0, true)
+
+	as.populateAuthors("")
+	return as
+}
+
+func (as *AuthorsScreen) populateAuthors(filter string) {
+	as.list.Clear()
+	authors := as.data.Authors()
+	if len(authors) == 0 {
+		as.authors = nil
+		as.split.show("")
+		as.detail.SetText("")
+		as.list.AddItem("No authors. Set author_dirs for notes kept in a directory per person.", "", 0, nil)
+		return
+	}
+
+	items := make([]fuzzyItem, 0, len(authors))
+	for _, author := range authors {
+		score, positions, ok := fuzzyMatch(filter, author)
+		if !ok {
+			continue
+		}
+		items = append(items, fuzzyItem{label: author, score: score, positions: positions})
+	}
+	rankFuzzyItems(items, filter)
+	as.authors = as.authors[:0]
+	for _, item := range items {
+		author := item.label
+		as.authors = append(as.authors, author)
+		as.list.AddItem(highlightMatches(author, item.positions), "", 0, func() {
+			as.showDetail(author)
+		})
+	}
+	as.preview(as.list.GetCurrentItem())
+}
+
+func (as *AuthorsScreen) preview(index int) {
+	if index < 0 || index >= len(as.authors) {
+		as.split.show("")
+		return
+	}
+	as.split.show(renderAuthor(as.authors[index], as.data))
+}
+
+func (as *AuthorsScreen) showDetail(author string) {
+	summary := renderAuthor(author, as.data)
+	if as.split.active {
+		as.split.show(summary)
+		as.app.SetFocus(as.split.preview)
+		return
+	}
+	as.shown = author
+	as.detail.SetText(summary)
+	as.innerPages.SwitchToPage("detail")
+	as.app.SetFocus(as.detail)
+}
+
+// refresh re-reads the notes, keeping the filter, the selection and the
+// author on the detail page.
+func (as *AuthorsScreen) refresh() {
+	var highlighted string
+	if index := as.list.GetCurrentItem(); index >= 0 && index < len(as.authors) {
+		highlighted = as.authors[index]
+	}
+	as.populateAuthors(as.filter.GetText())
+	if i := slices.Index(as.authors, highlighted); i >= 0 {
+		as.list.SetCurrentItem(i)
+	}
+	if name, _ := as.innerPages.GetFrontPage(); name == "detail" {
+		as.detail.SetText(renderAuthor(as.shown, as.data))
+	}
+}
+
+func (as *AuthorsScreen) restyle() {
+	restyle(as.Root, as.filter, as.split)
+}
+
+func (as *AuthorsScreen) handleEsc() {
+	switch as.app.GetFocus() {
+	case as.filter, as.split.preview:
+		as.app.SetFocus(as.list)
+		return
+	}
+	name, _ := as.innerPages.GetFrontPage()
+	if name == "detail" {
+		as.innerPages.SwitchToPage("list")
+		as.app.SetFocus(as.list)
+	}
+}
+
+func (as *AuthorsScreen) focusFilter() {
+	as.app.SetFocus(as.filter)
+}
+
+// yankText is every entry of the open or highlighted author.
+func (as *AuthorsScreen) yankText() (text, what string) {
+	author := as.shown
+	if name, _ := as.innerPages.GetFrontPage(); name != "detail" {
+		index := as.list.GetCurrentItem()
+		if index < 0 || index >= len(as.authors) {
+			return "", ""
+		}
+		author = as.authors[index]
+	}
+	own := filterAuthor(*as.data, author)
+	parts := make([]string, 0, len(own.Projects))
+	for _, name := range own.ProjectNames() {
+		parts = append(parts, markdownProject(own.Projects[name]))
+	}
+	return strings.Join(parts, "\n"), author + "'s entries"
+}
+
+// renderAuthor summarises one person's notes: how much they wrote and over
+// what span, then each project and task they touched with when.
+func renderAuthor(author string, data *TrailData) string {
+	own := filterAuthor(*data, author)
+	entries := own.Entries()
+	if len(entries) == 0 {
+		return author
+	}
+	days := len(own.Dates())
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s  %d %s on %d %s, %s\n", author,
+		len(entries), plural(len(entries), "entry", "entries"),
+		days, plural(days, "day", "days"),
+		dateSpan(entries[0].Date, entries[len(entries)-1].Date, longDate))
+	for _, projectName := range own.ProjectNames() {
+		project := own.Projects[projectName]
+		taskNames := project.TaskNames()
+		width := 0
+		for _, name := range taskNames {
+			width = max(width, len(name))
+		}
+		fmt.Fprintf(&sb, "\n@%s\n", projectName)
+		for _, name := range taskNames {
+			taskEntries := project.Tasks[name]
+			fmt.Fprintf(&sb, "  +%-*s  %d %s, %s\n", width, name,
+				len(taskEntries), plural(len(taskEntries), "entry", "entries"),
+				dateSpan(taskEntries[0].Date, taskEntries[len(taskEntries)-1].Date, shortDate))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// dateSpan shows first – last, or a single date when they are the same.
+func dateSpan(first, last time.Time, layout string) string {
+	if first.Equal(last) {
+		return first.Format(layout)
+	}
+	return first.Format(layout) + " – " + last.Format(layout)
+}
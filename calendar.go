@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- CalendarScreen ---
+
+// calendarWeeks is how many week columns the heatmap covers, a little over a
+// year so the current week and the same week last year are both visible.
+const calendarWeeks = 53
+
+type CalendarScreen struct {
+	Root    *tview.Grid
+	heatmap *heatmapView
+	preview *tview.TextView
+	data    *TrailData
+	app     *tview.Application
+}
+
+// newCalendarScreen builds the heatmap screen. onOpen is called with the
+// highlighted date when Enter is pressed.
+func newCalendarScreen(data *TrailData, app *tview.Application, onOpen func(time.Time)) *CalendarScreen {
+	cs := &CalendarScreen{data: data, app: app}
+
+	cs.preview = tview.NewTextView().SetScrollable(true)
+	cs.heatmap = newHeatmapView(entryCountsByDate(data))
+	cs.heatmap.changed = cs.showPreview
+	cs.heatmap.selected = onOpen
+
+	cs.Root = tview.NewGrid().SetRows(9, 0).SetColumns(0).SetBorders(true)
+	cs.Root.AddItem(cs.heatmap, 0, 0, 1, 1, 0, 0, true)
+	cs.Root.AddItem(cs.preview, 1, 0, 1, 1, 0, 0, false)
+
+	cs.showPreview(cs.heatmap.cursor)
+	return cs
+}
+
+func (cs *CalendarScreen) showPreview(date time.Time) {
+	count := cs.heatmap.counts[date]
+	header := fmt.Sprintf("%s (%d %s)", date.Format(longDate+" Monday"), count, plural(count, "entry", "entries"))
+	cs.preview.SetText(header + "\n\n" + renderDaySummary(date, cs.data))
+	cs.preview.ScrollToBeginning()
+}
+
+// refresh re-counts the entries, keeping the cursor where it is.
+func (cs *CalendarScreen) refresh() {
+	cs.heatmap.setCounts(entryCountsByDate(cs.data))
+	cs.showPreview(cs.heatmap.cursor)
+}
+
+func (cs *CalendarScreen) restyle() {
+	restyle(cs.Root, cs.heatmap, cs.preview)
+}
+
+// entryCountsByDate counts entries per day across every project and task.
+func entryCountsByDate(data *TrailData) map[time.Time]int {
+	counts := make(map[time.Time]int)
+	for _, project := range data.Projects {
+		for _, entries := range project.Tasks {
+			for _, entry := range entries {
+				counts[entry.Date]++
+			}
+		}
+	}
+	return counts
+}
+
+// yankText is the summary of the highlighted day.
+func (cs *CalendarScreen) yankText() (text, what string) {
+	return markdownDay(cs.heatmap.cursor, cs.data), cs.heatmap.cursor.Format(longDate)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// heatmapLabelWidth is the space left of the grid for weekday labels.
+const heatmapLabelWidth = 4
+
+// heatmapView draws a GitHub-style contribution grid: one column per week,
+// Monday at the top, with a movable cursor over the days.
+type heatmapView struct {
+	*tview.Box
+	counts   map[time.Time]int
+	maxCount int
+	today    time.Time
+	start    time.Time // Monday of the first (leftmost) week
+	cursor   time.Time
+	lastWeek int // index of the rightmost visible week column
+	visible  int // week columns shown on the last draw
+
+	changed  func(time.Time)
+	selected func(time.Time)
+}
+
+func newHeatmapView(counts map[time.Time]int) *heatmapView {
+	today := currentDay()
+	monday := today.AddDate(0, 0, -weekdayIndex(today))
+	hv := &heatmapView{
+		Box:      tview.NewBox(),
+		today:    today,
+		start:    monday.AddDate(0, 0, -7*(calendarWeeks-1)),
+		cursor:   today,
+		lastWeek: calendarWeeks - 1,
+	}
+	hv.setCounts(counts)
+	return hv
+}
+
+// setCounts replaces the entry counts the shades are worked out from.
+func (hv *heatmapView) setCounts(counts map[time.Time]int) {
+	hv.counts = counts
+	hv.maxCount = 0
+	for date, n := range counts {
+		if !date.Before(hv.start) && !date.After(hv.today) {
+			hv.maxCount = max(hv.maxCount, n)
+		}
+	}
+}
+
+// weekdayIndex returns 0 for Monday through 6 for Sunday.
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// level buckets a day's entry count into 0 (none) through 4 (busiest),
+// relative to the busiest day in the visible year.
+func (hv *heatmapView) level(count int) int {
+	if count <= 0 || hv.maxCount == 0 {
+		return 0
+	}
+	return min(4, 1+(count-1)*4/hv.maxCount)
+}
+
+// paletteHeatmap is the heatmap for themes of palette colours, which can't
+// be blended: grey for empty days, then dark to bright blues and cyans.
+var paletteHeatmap = [5]tcell.Color{tcell.ColorGray, tcell.ColorNavy, tcell.ColorTeal, tcell.ColorBlue, tcell.ColorAqua}
+
+// heatmapColors derives the five shades from the active theme, running from
+// the border colour for empty days to the accent colour for the busiest ones.
+// Themes not in true colour get paletteHeatmap instead.
+func heatmapColors() [5]tcell.Color {
+	if !theme.Background.IsRGB() || !theme.Border.IsRGB() || !theme.Accent.IsRGB() {
+		return paletteHeatmap
+	}
+	var colors [5]tcell.Color
+	colors[0] = blendColor(theme.Background, theme.Border, 0.6)
+	for i := 1; i < 5; i++ {
+		colors[i] = blendColor(theme.Border, theme.Accent, float64(i)/4)
+	}
+	return colors
+}
+
+// blendColor mixes a towards b by t (0 gives a, 1 gives b).
+func blendColor(a, b tcell.Color, t float64) tcell.Color {
+	ar, ag, ab := a.RGB()
+	br, bg, bb := b.RGB()
+	mix := func(x, y int32) int32 {
+		return x + int32(float64(y-x)*t)
+	}
+	return tcell.NewRGBColor(mix(ar, br), mix(ag, bg), mix(ab, bb))
+}
+
+func (hv *heatmapView) Draw(screen tcell.Screen) {
+	hv.DrawForSubclass(screen, hv)
+	x, y, width, height := hv.GetInnerRect()
+	if height < 8 {
+		return
+	}
+
+	visible := min(calendarWeeks, (width-heatmapLabelWidth)/2)
+	hv.visible = visible
+	if visible <= 0 {
+		return
+	}
+
+	// Keep the cursor's week in view, preferring to show the latest weeks.
+	cursorWeek := int(hv.cursor.Sub(hv.start).Hours()/24) / 7
+	hv.lastWeek = max(hv.lastWeek, visible-1)
+	if cursorWeek > hv.lastWeek {
+		hv.lastWeek = cursorWeek
+	}
+	if cursorWeek < hv.lastWeek-visible+1 {
+		hv.lastWeek = cursorWeek + visible - 1
+	}
+	hv.lastWeek = min(hv.lastWeek, calendarWeeks-1)
+	firstWeek := hv.lastWeek - visible + 1
+
+	bg := tview.Styles.PrimitiveBackgroundColor
+	dim := tview.Styles.TertiaryTextColor
+	colors := heatmapColors()
+
+	for row, label := range []string{"Mon", "", "Wed", "", "Fri", "", "Sun"} {
+		tview.Print(screen, label, x, y+1+row, heatmapLabelWidth, tview.AlignLeft, dim)
+	}
+
+	lastMonth := time.Month(0)
+	for col := 0; col < visible; col++ {
+		week := firstWeek + col
+		monday := hv.start.AddDate(0, 0, 7*week)
+		cx := x + heatmapLabelWidth + col*2
+
+		if monday.Month() != lastMonth {
+			lastMonth = monday.Month()
+			if cx+3 <= x+width {
+				tview.Print(screen, monday.Format("Jan"), cx, y, 3, tview.AlignLeft, dim)
+			}
+		}
+
+		for row := 0; row < 7; row++ {
+			date := monday.AddDate(0, 0, row)
+			if date.After(hv.today) {
+				continue
+			}
+			style := tcell.StyleDefault.Background(bg).Foreground(colors[hv.level(hv.counts[date])])
+			if date.Equal(hv.cursor) {
+				style = style.Background(theme.Highlight)
+			}
+			screen.SetContent(cx, y+1+row, '■', nil, style)
+		}
+	}
+
+	legend := "Less "
+	for _, c := range colors {
+		legend += colorTag(c) + "■[-]"
+	}
+	legend += " More"
+	tview.Print(screen, legend, x, y+8, width, tview.AlignRight, dim)
+}
+
+// moveCursor shifts the cursor by days, clamped to the visible year.
+func (hv *heatmapView) moveCursor(days int) {
+	next := hv.cursor.AddDate(0, 0, days)
+	if next.Before(hv.start) {
+		next = hv.start
+	}
+	if next.After(hv.today) {
+		next = hv.today
+	}
+	if next.Equal(hv.cursor) {
+		return
+	}
+	hv.cursor = next
+	if hv.changed != nil {
+		hv.changed(next)
+	}
+}
+
+func (hv *heatmapView) jumpTo(date time.Time) {
+	hv.moveCursor(int(date.Sub(hv.cursor).Hours() / 24))
+}
+
+// dateAt returns the day drawn at screen position x, y, if any.
+func (hv *heatmapView) dateAt(x, y int) (time.Time, bool) {
+	rectX, rectY, _, _ := hv.GetInnerRect()
+	col, row := (x-rectX-heatmapLabelWidth)/2, y-rectY-1
+	if x < rectX+heatmapLabelWidth || col >= hv.visible || row < 0 || row > 6 {
+		return time.Time{}, false
+	}
+	week := hv.lastWeek - hv.visible + 1 + col
+	date := hv.start.AddDate(0, 0, 7*week+row)
+	if date.Before(hv.start) || date.After(hv.today) {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (hv *heatmapView) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return hv.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		x, y := event.Position()
+		if !hv.InRect(x, y) {
+			return false, nil
+		}
+		switch action {
+		case tview.MouseLeftDown:
+			setFocus(hv)
+		case tview.MouseLeftClick:
+			if date, ok := hv.dateAt(x, y); ok {
+				hv.jumpTo(date)
+			}
+		case tview.MouseLeftDoubleClick:
+			if date, ok := hv.dateAt(x, y); ok && hv.selected != nil {
+				hv.jumpTo(date)
+				hv.selected(date)
+			}
+		case tview.MouseScrollUp:
+			hv.moveCursor(-7)
+		case tview.MouseScrollDown:
+			hv.moveCursor(7)
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+func (hv *heatmapView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return hv.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyLeft:
+			hv.moveCursor(-7)
+		case tcell.KeyRight:
+			hv.moveCursor(7)
+		case tcell.KeyUp:
+			hv.moveCursor(-1)
+		case tcell.KeyDown:
+			hv.moveCursor(1)
+		case tcell.KeyEnter:
+			if hv.selected != nil {
+				hv.selected(hv.cursor)
+			}
+		case tcell.KeyPgUp:
+			hv.moveCursor(-28)
+		case tcell.KeyPgDn:
+			hv.moveCursor(28)
+		case tcell.KeyHome:
+			hv.jumpTo(hv.start)
+		case tcell.KeyEnd:
+			hv.jumpTo(hv.today)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"thesecondreal0/trail/notes"
+)
+
+// --- Clipboard ---
+
+// osc52 builds the escape sequence that asks the terminal to put text on the
+// system clipboard. The terminal on the user's side does the copy, so this
+// works over SSH too. tmux and screen only forward it when it is wrapped in
+// their passthrough sequence; tmux with set-clipboard on also takes the
+// plain form, so both are sent.
+func osc52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case os.Getenv("TMUX") != "":
+		return seq + "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case os.Getenv("STY") != "":
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+// copyToClipboard writes the OSC 52 sequence straight to the terminal,
+// falling back to tcell's own clipboard support when there is no tty (as
+// with a simulation screen).
+func copyToClipboard(screen tcell.Screen, text string) {
+	if tty, ok := screen.Tty(); ok {
+		if _, err := tty.Write([]byte(osc52(text))); err == nil {
+			return
+		}
+	}
+	screen.SetClipboard([]byte(text))
+}
+
+// --- Markdown ---
+
+// markdownEntries writes a task's entries under a heading per date. Entries
+// are expected to be sorted newest first.
+func markdownEntries(sb *strings.Builder, entries []notes.Entry) {
+	var current time.Time
+	for _, entry := range entries {
+		if !entry.Date.Equal(current) {
+			current = entry.Date
+			fmt.Fprintf(sb, "\n### %s\n\n", current.Format(longDate))
+		}
+		sb.WriteString(entry.Content + "\n")
+	}
+}
+
+// markdownTask renders a task's whole history.
+func markdownTask(project, task string, entries []notes.Entry) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## @%s +%s\n", project, task)
+	markdownEntries(&sb, notes.NewestFirst(entries))
+	return sb.String()
+}
+
+// markdownProject renders every task of a project, in name order.
+func markdownProject(project notes.Project) string {
+	taskNames := project.TaskNames()
+	parts := make([]string, len(taskNames))
+	for i, name := range taskNames {
+		parts[i] = markdownTask(project.Name, name, project.Tasks[name])
+	}
+	return strings.Join(parts, "\n")
+}
+
+// markdownDay is renderDaySummary as markdown: the date as a heading and
+// each task's entries under an @project +task heading.
+func markdownDay(date time.Time, data *TrailData) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n", date.Format(longDate))
+	for _, projectName := range data.ProjectNames() {
+		project := data.Projects[projectName]
+		for _, taskName := range project.TaskNames() {
+			heading := false
+			for _, entry := range project.Tasks[taskName] {
+				if !entry.Date.Equal(date) {
+					continue
+				}
+				if !heading {
+					fmt.Fprintf(&sb, "\n### @%s +%s\n\n", projectName, taskName)
+					heading = true
+				}
+				sb.WriteString(entry.Content + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// markdownRecentNode renders a recent box: a project's tasks, a task's
+// days, or a single day of a task.
+func markdownRecentNode(node *recentNode) string {
+	if node.isProject() {
+		parts := make([]string, len(node.children))
+		for i, child := range node.children {
+			parts[i] = markdownRecentNode(child)
+		}
+		return strings.Join(parts, "\n")
+	}
+	days := node.children
+	if node.isDate() {
+		days = []*recentNode{node}
+	}
+	var entries []notes.Entry
+	for _, day := range days {
+		entries = append(entries, day.entries...)
+	}
+	return markdownTask(node.project, node.task, entries)
+}
+
+// colorTagRegex matches the colour tags trail puts in rendered text.
+var colorTagRegex = regexp.MustCompile(`\[[a-zA-Z0-9#:-]*\]`)
+
+// stripColorTags turns rendered text back into plain text.
+func stripColorTags(text string) string {
+	return colorTagRegex.ReplaceAllString(text, "")
+}
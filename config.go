@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+
+	"thesecondreal0/trail/notes"
+)
+
+// Config is the user configuration read from config.toml. Every field is
+// optional; anything left out keeps its built-in default.
+type Config struct {
+	NotesDirs        []string              `toml:"notes_dirs"`
+	SeparateProjects bool                  `toml:"separate_projects"`
+	AuthorDirs       bool                  `toml:"author_dirs"`
+	DefaultTask      string                `toml:"default_task"`
+	DefaultScreen    string                `toml:"default_screen"`
+	RecentDays       int                   `toml:"recent_days"`
+	Dates            DateConfig            `toml:"dates"`
+	Theme            string                `toml:"theme"`
+	Colors           map[string]string     `toml:"colors"`
+	Keys             map[string]keyBinding `toml:"keys"`
+
+	// defaultTaskSet is whether the file set default_task, so that an
+	// empty one turns the default task off rather than falling back.
+	defaultTaskSet bool
+
+	// Resolved from the raw fields above by validate.
+	theme   Theme
+	keys    *keymap
+	sources []noteSource // the notes dirs, or the --archive given instead
+}
+
+// DateConfig holds Go time layouts for reading and showing dates.
+type DateConfig struct {
+	Filename string `toml:"filename"` // date in note file names
+	Short    string `toml:"short"`    // headings above a task's entries
+	Long     string `toml:"long"`     // day lists, recent boxes and other screens
+}
+
+// defaultConfigPath is $XDG_CONFIG_HOME/trail/config.toml, falling back to
+// ~/.config when XDG_CONFIG_HOME is unset.
+func defaultConfigPath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "trail", "config.toml"), nil
+}
+
+// loadConfig reads and validates the config file at path. A missing file is
+// only an error when the path was given explicitly.
+func loadConfig(path string, explicit bool) (*Config, error) {
+	cfg := &Config{}
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			cfg = &Config{}
+		} else {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("%s: %s", path, parseErr.ErrorWithPosition())
+			}
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return nil, fmt.Errorf("%s: unknown key %s", path, strings.Join(keys, ", "))
+	}
+	cfg.defaultTaskSet = md.IsDefined("default_task")
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate fills in defaults and checks every field, resolving the theme and
+// key bindings.
+func (cfg *Config) validate() error {
+	if err := cfg.resolveSources(); err != nil {
+		return fmt.Errorf("notes_dirs: %w", err)
+	}
+
+	// An empty default screen is resolved once the pins are loaded.
+	if cfg.DefaultScreen != "" && !slices.Contains(screenNames, cfg.DefaultScreen) {
+		return fmt.Errorf("default_screen: unknown screen %q (want one of %s)", cfg.DefaultScreen, strings.Join(screenNames, ", "))
+	}
+
+	// default_task = "" turns it off, so project-only headings are reported.
+	if cfg.DefaultTask == "" && !cfg.defaultTaskSet {
+		cfg.DefaultTask = "general"
+	}
+	if cfg.DefaultTask != "" && !notes.ValidName(cfg.DefaultTask) {
+		return fmt.Errorf("default_task: %q may only contain letters, digits, _, . and -", cfg.DefaultTask)
+	}
+
+	if cfg.RecentDays == 0 {
+		cfg.RecentDays = 28
+	}
+	if cfg.RecentDays < 0 {
+		return fmt.Errorf("recent_days: must be positive, got %d", cfg.RecentDays)
+	}
+
+	for _, layout := range []struct {
+		name  string
+		value *string
+		def   string
+	}{
+		{"dates.filename", &cfg.Dates.Filename, "06-01-02"},
+		{"dates.short", &cfg.Dates.Short, "06-01-02"},
+		{"dates.long", &cfg.Dates.Long, "2006-01-02"},
+	} {
+		if *layout.value == "" {
+			*layout.value = layout.def
+		}
+		if err := checkLayout(*layout.value); err != nil {
+			return fmt.Errorf("%s: %w", layout.name, err)
+		}
+	}
+	if strings.Trim(cfg.Dates.Filename, "0123456789-_.") != "" {
+		return fmt.Errorf("dates.filename: %q must be numeric, e.g. 06-01-02 or 20060102", cfg.Dates.Filename)
+	}
+
+	if cfg.Theme == "" {
+		cfg.Theme = tokyoNight.Name
+	}
+	i := slices.IndexFunc(builtinThemes, func(t Theme) bool { return t.Name == cfg.Theme })
+	if i < 0 {
+		names := make([]string, len(builtinThemes))
+		for i, t := range builtinThemes {
+			names[i] = t.Name
+		}
+		return fmt.Errorf("theme: unknown theme %q (want one of %s)", cfg.Theme, strings.Join(names, ", "))
+	}
+	cfg.theme = builtinThemes[i]
+	for name, value := range cfg.Colors {
+		field, ok := themeColors[name]
+		if !ok {
+			return fmt.Errorf("colors.%s: unknown colour name", name)
+		}
+		color := tcell.GetColor(value)
+		if color == tcell.ColorDefault {
+			return fmt.Errorf("colors.%s: invalid colour %q (use #rrggbb or a colour name)", name, value)
+		}
+		*field(&cfg.theme) = color
+	}
+
+	keys, err := newKeymap(cfg.Keys)
+	if err != nil {
+		return err
+	}
+	cfg.keys = keys
+	return nil
+}
+
+// resolveSources checks the notes dirs, defaulting to the current
+// directory, and makes a source of each. A directory given twice, under any
+// path, is read once.
+func (cfg *Config) resolveSources() error {
+	if len(cfg.NotesDirs) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfg.NotesDirs = []string{cwd}
+	}
+	cfg.sources = nil
+	var dirs []string
+	seen := make(map[string]bool) // absolute paths, so ~/notes and /home/me/notes are one
+	for _, dir := range cfg.NotesDirs {
+		dir = expandHome(dir)
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		dir = filepath.Clean(dir)
+		dirs = append(dirs, dir)
+		cfg.sources = append(cfg.sources, noteSource{name: dir, fsys: os.DirFS(dir)})
+	}
+	cfg.NotesDirs = dirs
+	for i, label := range sourceLabelsFor(cfg.NotesDirs) {
+		cfg.sources[i].label = label
+	}
+	return nil
+}
+
+// sourceLabelsFor names each directory by its last path element, taking in
+// parent directories, joined with "-", until no two names are the same.
+func sourceLabelsFor(dirs []string) []string {
+	parts := make([][]string, len(dirs))
+	depth := make([]int, len(dirs))
+	for i, dir := range dirs {
+		parts[i] = strings.Split(strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/"), "/")
+		depth[i] = 1
+	}
+	labels := make([]string, len(dirs))
+	for {
+		for i := range dirs {
+			labels[i] = strings.Join(parts[i][max(0, len(parts[i])-depth[i]):], "-")
+		}
+		grown := false
+		for i := range dirs {
+			for j := range dirs {
+				if i != j && labels[i] == labels[j] && depth[i] < len(parts[i]) {
+					depth[i]++
+					grown = true
+					break
+				}
+			}
+		}
+		if !grown {
+			return labels
+		}
+	}
+}
+
+// checkLayout makes sure a Go time layout survives a round trip, which
+// catches typos such as "YY-MM-DD".
+func checkLayout(layout string) error {
+	ref := time.Date(2025, time.February, 14, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, ref.Format(layout))
+	if err != nil || !parsed.Equal(ref) {
+		return fmt.Errorf("%q is not a Go date layout with year, month and day (e.g. 2006-01-02)", layout)
+	}
+	return nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
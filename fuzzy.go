@@ -0,0 +1,176 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rivo/tview"
+)
+
+// Scoring weights for fuzzyMatch, loosely modelled on fzf: every matched rune
+// is worth scoreMatch, runes at the start of a word or right after the
+// previous match earn a bonus, and skipped runes between matches cost a
+// little.
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8
+	bonusCamel        = 7
+	bonusConsecutive  = 4
+	penaltyGapStart   = 3
+	penaltyGapExtends = 1
+)
+
+// fuzzyMatch reports whether every rune of pattern appears in s in order,
+// ignoring case. When it does, it returns the score of the best alignment and
+// the rune indices of s that were matched, for highlighting.
+func fuzzyMatch(pattern, s string) (int, []int, bool) {
+	p := lowerRunes(pattern)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	orig := []rune(s)
+	text := lowerRunes(s)
+	if len(p) > len(text) {
+		return 0, nil, false
+	}
+
+	bonus := make([]int, len(orig))
+	for j, r := range orig {
+		switch {
+		case j == 0:
+			bonus[j] = bonusBoundary
+		case strings.ContainsRune("-_./ ", orig[j-1]):
+			bonus[j] = bonusBoundary
+		case unicode.IsUpper(r) && unicode.IsLower(orig[j-1]):
+			bonus[j] = bonusCamel
+		}
+	}
+
+	// score[i][j] is the best score for matching p[:i+1] with p[i] landing on
+	// text[j]; prev[i][j] is where p[i-1] landed in that alignment.
+	const none = -1 << 31
+	score := make([][]int, len(p))
+	prev := make([][]int, len(p))
+	for i := range p {
+		score[i] = make([]int, len(text))
+		prev[i] = make([]int, len(text))
+		for j := range text {
+			score[i][j] = none
+			prev[i][j] = -1
+			if text[j] != p[i] {
+				continue
+			}
+			base := scoreMatch + bonus[j]
+			if i == 0 {
+				score[i][j] = base
+				continue
+			}
+			for k := i - 1; k < j; k++ {
+				if score[i-1][k] == none {
+					continue
+				}
+				cand := score[i-1][k] + base
+				if gap := j - k - 1; gap == 0 {
+					cand += bonusConsecutive
+				} else {
+					cand -= penaltyGapStart + (gap-1)*penaltyGapExtends
+				}
+				if cand > score[i][j] {
+					score[i][j] = cand
+					prev[i][j] = k
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	best, end := none, -1
+	for j := range text {
+		if score[last][j] > best {
+			best, end = score[last][j], j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, len(p))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = j
+		j = prev[i][j]
+	}
+	return best, positions, true
+}
+
+// lowerRunes lowercases s rune by rune, so indices line up with []rune(s).
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// highlightMatches wraps the runes of s at positions in a tview color tag so
+// fuzzy matches stand out in list items. The rest of s is escaped, so a name
+// that looks like a tag shows as written.
+func highlightMatches(s string, positions []int) string {
+	if len(positions) == 0 {
+		return tview.Escape(s)
+	}
+	match := "[" + theme.Highlight.String() + "::b]"
+	const reset = "[-::-]"
+
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var sb, run strings.Builder
+	inMatch := false
+	for i, r := range []rune(s) {
+		if matched[i] != inMatch {
+			sb.WriteString(tview.Escape(run.String()))
+			run.Reset()
+			inMatch = matched[i]
+			if inMatch {
+				sb.WriteString(match)
+			} else {
+				sb.WriteString(reset)
+			}
+		}
+		run.WriteRune(r)
+	}
+	sb.WriteString(tview.Escape(run.String()))
+	if inMatch {
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+// fuzzyItem is one candidate in a filtered list.
+type fuzzyItem struct {
+	label     string
+	score     int
+	positions []int
+	lastEntry time.Time
+}
+
+// rankFuzzyItems orders items best match first, breaking ties by the most
+// recent entry and then by label. With an empty filter every score is zero,
+// so items are simply kept alphabetical.
+func rankFuzzyItems(items []fuzzyItem, filter string) {
+	slices.SortStableFunc(items, func(a, b fuzzyItem) int {
+		if filter != "" {
+			if a.score != b.score {
+				return b.score - a.score
+			}
+			if c := b.lastEntry.Compare(a.lastEntry); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.label, b.label)
+	})
+}
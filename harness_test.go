@@ -0,0 +1,211 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+)
+
+// fixtureNow is the clock the tests run at. The notes in testdata/notes are
+// dated around it: a Friday, with entries earlier in the same week.
+var fixtureNow = time.Date(2025, time.February, 14, 10, 0, 0, 0, time.UTC)
+
+// syncKey is never bound to anything. harness.sync sends it through the
+// event loop to know every key before it has been handled and drawn.
+const syncKey = tcell.KeyF64
+
+// TestMain keeps the parser's warnings about the fixtures out of the test
+// output.
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// harness runs the real application on a simulation screen, reading the
+// fixture notes.
+type harness struct {
+	t      *testing.T
+	screen tcell.SimulationScreen
+	app    *tview.Application
+	ui     *UI
+	synced chan struct{}
+	done   chan struct{}
+}
+
+// newHarness starts trail on a width×height screen showing the given
+// screen first. Any configure funcs adjust the config before it is
+// validated.
+func newHarness(t *testing.T, width, height int, screenName string, configure ...func(*Config)) *harness {
+	t.Helper()
+
+	// Package state that screens read, reset to its defaults.
+	now = func() time.Time { return fixtureNow }
+	pins = &pinSet{}
+	parseCache = nil
+	splitLayout = false
+	t.Cleanup(func() { now = time.Now })
+
+	cfg := &Config{NotesDirs: []string{"testdata/notes"}, DefaultScreen: screenName}
+	for _, f := range configure {
+		f(cfg)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	applyTheme(cfg.theme)
+	filenameDateLayout = cfg.Dates.Filename
+	shortDate = cfg.Dates.Short
+	longDate = cfg.Dates.Long
+	setSourceLabels(cfg.sources)
+	data, err := loadTrailData(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SetScreen initialises the screen, which resets its size.
+	screen := tcell.NewSimulationScreen("UTF-8")
+	h := &harness{
+		t:      t,
+		screen: screen,
+		app:    tview.NewApplication().SetScreen(screen),
+		synced: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	screen.SetSize(width, height)
+	h.ui = newUI(h.app, &data, cfg)
+	capture := h.app.GetInputCapture()
+	h.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == syncKey {
+			h.synced <- struct{}{}
+			return nil
+		}
+		return capture(event)
+	})
+
+	go func() {
+		defer close(h.done)
+		if err := h.app.Run(); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(func() {
+		h.app.Stop()
+		<-h.done
+	})
+	h.sync()
+	return h
+}
+
+// sync waits until every event sent so far has been handled and drawn.
+func (h *harness) sync() {
+	h.t.Helper()
+	h.app.QueueEvent(tcell.NewEventKey(syncKey, 0, tcell.ModNone))
+	select {
+	case <-h.synced:
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("timed out waiting for the event loop")
+	}
+	// Queued updates such as status messages draw on their own; one more
+	// round trip lets the last draw finish.
+	h.app.QueueUpdateDraw(func() {})
+}
+
+// press sends keys written as in config.toml, e.g. "Tab", "g g" or "Ctrl-D",
+// and waits for them to be handled.
+func (h *harness) press(keys string) {
+	h.t.Helper()
+	seq, err := parseKeySeq(keys)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	for _, k := range seq {
+		h.app.QueueEvent(tcell.NewEventKey(k.key, k.ch, tcell.ModNone))
+	}
+	h.sync()
+}
+
+// typeText types s into whatever has focus.
+func (h *harness) typeText(s string) {
+	h.t.Helper()
+	for _, r := range s {
+		h.app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	h.sync()
+}
+
+// lines returns the screen contents, one string per row with trailing
+// spaces removed. Wide characters take one rune for both of their cells.
+func (h *harness) lines() []string {
+	cells, width, height := h.screen.GetContents()
+	lines := make([]string, height)
+	for y := range height {
+		var sb strings.Builder
+		for x := 0; x < width; x++ {
+			cell := cells[y*width+x]
+			if len(cell.Runes) == 0 {
+				sb.WriteByte(' ')
+				continue
+			}
+			sb.WriteString(string(cell.Runes))
+			if w := runewidth.StringWidth(string(cell.Runes)); w > 1 {
+				x += w - 1
+			}
+		}
+		lines[y] = strings.TrimRight(sb.String(), " ")
+	}
+	return lines
+}
+
+func (h *harness) text() string {
+	return strings.Join(h.lines(), "\n")
+}
+
+// contains fails the test unless every string in want is on screen.
+func (h *harness) contains(want ...string) {
+	h.t.Helper()
+	text := h.text()
+	for _, w := range want {
+		if !strings.Contains(text, w) {
+			h.t.Fatalf("screen does not show %q:\n%s", w, text)
+		}
+	}
+}
+
+// lacks fails the test if any string in unwanted is on screen.
+func (h *harness) lacks(unwanted ...string) {
+	h.t.Helper()
+	text := h.text()
+	for _, u := range unwanted {
+		if strings.Contains(text, u) {
+			h.t.Fatalf("screen shows %q:\n%s", u, text)
+		}
+	}
+}
+
+// clipboard returns what was last copied. A simulation screen has no tty,
+// so copies go to tcell's clipboard rather than out as OSC 52.
+func (h *harness) clipboard() string {
+	return string(h.screen.GetClipboardData())
+}
+
+// selected returns the text of the first row drawn with the list
+// selection colours, which is how tview marks the current list item.
+func (h *harness) selected() string {
+	cells, width, height := h.screen.GetContents()
+	for y := range height {
+		for x := range width {
+			_, bg, _ := cells[y*width+x].Style.Decompose()
+			if bg == tview.Styles.PrimaryTextColor && len(cells[y*width+x].Runes) > 0 && cells[y*width+x].Runes[0] != ' ' {
+				return strings.Trim(h.lines()[y], "│ ")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Action is something a key binding can trigger.
+type Action struct {
+	Name     string   // name used under [keys] in config.toml
+	Help     string   // shown in the ? overlay
+	Screens  []string // screens it applies to; nil means every screen
+	Defaults []string // default key sequences, e.g. "g g" or "Ctrl-D"
+}
+
+// actions is the registry of every bindable action, in the order the help
+// overlay lists them.
+var actions = []Action{
+	{Name: "next_screen", Help: "next screen", Defaults: []string{"Tab"}},
+	{Name: "prev_screen", Help: "previous screen", Defaults: []string{"Backtab"}},
+	{Name: "back", Help: "go back / leave input", Defaults: []string{"Esc"}},
+	{Name: "open", Help: "open selected item", Defaults: []string{"Enter"}},
+	{Name: "edit", Help: "edit today's note in $EDITOR", Defaults: []string{"e"}},
+	{Name: "yank", Help: "copy selection as markdown", Defaults: []string{"y"}},
+	{Name: "theme", Help: "cycle theme", Defaults: []string{"T"}},
+	{Name: "source", Help: "cycle notes source", Defaults: []string{"s"}},
+	{Name: "author", Help: "cycle author", Defaults: []string{"a"}},
+	{Name: "help", Help: "show key bindings", Defaults: []string{"?"}},
+	{Name: "quit", Help: "quit", Defaults: []string{"q", "Ctrl-C"}},
+
+	{Name: "up", Help: "move up", Defaults: []string{"k", "Up"}},
+	{Name: "down", Help: "move down", Defaults: []string{"j", "Down"}},
+	{Name: "left", Help: "move left", Defaults: []string{"h", "Left"}},
+	{Name: "right", Help: "move right", Defaults: []string{"l", "Right"}},
+	{Name: "top", Help: "jump to top", Defaults: []string{"g g", "Home"}},
+	{Name: "bottom", Help: "jump to bottom", Defaults: []string{"G", "End"}},
+	{Name: "page_down", Help: "page down", Defaults: []string{"Ctrl-D", "PgDn"}},
+	{Name: "page_up", Help: "page up", Defaults: []string{"Ctrl-U", "PgUp"}},
+
+	{Name: "filter", Help: "focus filter input", Screens: []string{"projects", "tasks", "days", "recent", "stats", "authors"}, Defaults: []string{"/"}},
+	{Name: "pin", Help: "pin or unpin task", Screens: []string{"projects", "tasks"}, Defaults: []string{"p"}},
+	{Name: "split", Help: "toggle split layout", Screens: []string{"projects", "tasks", "days", "authors"}, Defaults: []string{"v"}},
+	{Name: "prev_week", Help: "previous week", Screens: []string{"week"}, Defaults: []string{"["}},
+	{Name: "next_week", Help: "next week", Screens: []string{"week"}, Defaults: []string{"]"}},
+	{Name: "today", Help: "jump to today", Screens: []string{"week", "calendar", "timeline"}, Defaults: []string{"t"}},
+	{Name: "first", Help: "jump to first activity", Screens: []string{"timeline"}, Defaults: []string{"H"}},
+	{Name: "last", Help: "jump to last activity", Screens: []string{"timeline"}, Defaults: []string{"L"}},
+	{Name: "expand", Help: "expand or collapse", Screens: []string{"recent", "timeline"}, Defaults: []string{"Space"}},
+	{Name: "prev_project", Help: "previous project box", Screens: []string{"recent"}, Defaults: []string{"["}},
+	{Name: "next_project", Help: "next project box", Screens: []string{"recent"}, Defaults: []string{"]"}},
+}
+
+// motionKeys maps movement actions onto the plain key every tview primitive
+// (and trail's own views) already understands, so remapping "down" to "n"
+// works everywhere without each view knowing about the bindings.
+var motionKeys = map[string]tcell.Key{
+	"open":      tcell.KeyEnter,
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+	"top":       tcell.KeyHome,
+	"bottom":    tcell.KeyEnd,
+	"page_down": tcell.KeyPgDn,
+	"page_up":   tcell.KeyPgUp,
+}
+
+func (a Action) appliesTo(screen string) bool {
+	return a.Screens == nil || slices.Contains(a.Screens, screen)
+}
+
+// keySpec is a parsed key: either a special key or a single rune.
+type keySpec struct {
+	key tcell.Key
+	ch  rune
+}
+
+func (k keySpec) matches(event *tcell.EventKey) bool {
+	if k.key == tcell.KeyRune {
+		return event.Key() == tcell.KeyRune && event.Rune() == k.ch
+	}
+	return event.Key() == k.key
+}
+
+func (k keySpec) String() string {
+	switch {
+	case k.key == tcell.KeyRune && k.ch == ' ':
+		return "Space"
+	case k.key == tcell.KeyRune:
+		return string(k.ch)
+	}
+	return tcell.KeyNames[k.key]
+}
+
+// keySeq is a binding of one or more keys pressed in turn, like "g g".
+type keySeq []keySpec
+
+func (seq keySeq) String() string {
+	parts := make([]string, len(seq))
+	for i, k := range seq {
+		parts[i] = k.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasPrefix reports whether the keys pressed so far start seq.
+func (seq keySeq) hasPrefix(pressed []*tcell.EventKey) bool {
+	if len(pressed) > len(seq) {
+		return false
+	}
+	for i, event := range pressed {
+		if !seq[i].matches(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseKey accepts a single character ("/", "v") or a tcell key name such as
+// "Tab", "Esc", "Enter", "F5" or "Ctrl-N", ignoring case.
+func parseKey(name string) (keySpec, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return keySpec{key: tcell.KeyRune, ch: r}, nil
+	}
+	switch strings.ToLower(name) {
+	case "shift-tab":
+		return keySpec{key: tcell.KeyBacktab}, nil
+	case "space":
+		return keySpec{key: tcell.KeyRune, ch: ' '}, nil
+	}
+	for key, keyName := range tcell.KeyNames {
+		if strings.EqualFold(keyName, name) {
+			return keySpec{key: key}, nil
+		}
+	}
+	return keySpec{}, fmt.Errorf("unknown key %q", name)
+}
+
+// parseKeySeq parses space-separated keys, e.g. "g g" or "Ctrl-W l".
+func parseKeySeq(s string) (keySeq, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty key binding")
+	}
+	seq := make(keySeq, len(fields))
+	for i, field := range fields {
+		k, err := parseKey(field)
+		if err != nil {
+			return nil, err
+		}
+		seq[i] = k
+	}
+	return seq, nil
+}
+
+// keyBinding is one action's entry under [keys]: a single key sequence or a
+// list of them.
+type keyBinding []string
+
+func (kb *keyBinding) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case string:
+		*kb = keyBinding{v}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("key bindings must be strings, got %v", item)
+			}
+			*kb = append(*kb, s)
+		}
+	default:
+		return fmt.Errorf("key binding must be a string or a list of strings, got %v", value)
+	}
+	return nil
+}
+
+// keySeqTimeout is how long a partly typed sequence such as "g g" waits for
+// its next key before it is dropped.
+const keySeqTimeout = time.Second
+
+// keymap resolves key presses to actions, tracking a partly typed sequence.
+type keymap struct {
+	bindings  map[string][]keySeq // by action name
+	pending   []*tcell.EventKey
+	pendingAt time.Time // when the last pending key was pressed
+}
+
+// newKeymap starts from the default bindings and applies overrides, which
+// replace an action's defaults entirely.
+func newKeymap(overrides map[string]keyBinding) (*keymap, error) {
+	km := &keymap{bindings: make(map[string][]keySeq, len(actions))}
+	for _, a := range actions {
+		for _, s := range a.Defaults {
+			seq, err := parseKeySeq(s)
+			if err != nil {
+				panic(err)
+			}
+			km.bindings[a.Name] = append(km.bindings[a.Name], seq)
+		}
+	}
+	for name, binding := range overrides {
+		if !slices.ContainsFunc(actions, func(a Action) bool { return a.Name == name }) {
+			return nil, fmt.Errorf("keys.%s: unknown action", name)
+		}
+		km.bindings[name] = nil
+		for _, s := range binding {
+			seq, err := parseKeySeq(s)
+			if err != nil {
+				return nil, fmt.Errorf("keys.%s: %w", name, err)
+			}
+			km.bindings[name] = append(km.bindings[name], seq)
+		}
+	}
+	return km, nil
+}
+
+// resolve feeds one key press into the keymap. It returns the action it
+// completes, or wait=true if it is the start of a longer sequence. Actions
+// bound to plain characters are skipped while typing in an input field.
+func (km *keymap) resolve(event *tcell.EventKey, screen string, typing bool) (action string, wait bool) {
+	if now().Sub(km.pendingAt) > keySeqTimeout {
+		km.pending = nil
+	}
+	pressed := append(km.pending, event)
+	km.pending = nil
+	longer := false
+	for _, a := range actions {
+		if !a.appliesTo(screen) {
+			continue
+		}
+		for _, seq := range km.bindings[a.Name] {
+			if typing && seq[0].key == tcell.KeyRune {
+				continue
+			}
+			if !seq.hasPrefix(pressed) {
+				continue
+			}
+			if len(seq) == len(pressed) {
+				return a.Name, false
+			}
+			longer = true
+		}
+	}
+	if longer {
+		km.pending, km.pendingAt = pressed, now()
+		return "", true
+	}
+	if len(pressed) > 1 {
+		// The earlier keys led nowhere; try this one on its own.
+		return km.resolve(event, screen, typing)
+	}
+	return "", false
+}
+
+// describe lists the key sequences bound to an action, e.g. "g g, Home".
+func (km *keymap) describe(name string) string {
+	seqs := km.bindings[name]
+	parts := make([]string, len(seqs))
+	for i, seq := range seqs {
+		parts[i] = seq.String()
+	}
+	return strings.Join(parts, ", ")
+}
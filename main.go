@@ -1,49 +1,54 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"slices"
-	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+	"thesecondreal0/trail/notes"
 )
 
 // --- Data Structures ---
 
-type Project struct {
-	Name  string
-	Tasks map[string][]Entry
-}
-
-type Entry struct {
-	Date    time.Time
-	Content string
-}
-
+// TrailData is the notes the UI shows, merged from every notes directory.
 type TrailData struct {
-	Projects map[string]Project
+	notes.Notes
+	Diagnostics []notes.Diagnostic // problems found while parsing
+	LoadedAt    time.Time
+
+	bySource map[string]*notes.Notes // each source's notes on their own, by name
 }
 
 // --- Helpers ---
 
-func vimList(list *tview.List) *tview.List {
-	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Rune() {
-		case 'j':
-			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
-		case 'k':
-			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
-		}
-		return event
-	})
-	return list
+// Date layouts of note file names and for display; config.toml can
+// override all three.
+var (
+	filenameDateLayout = notes.DefaultDateLayout
+	shortDate          = "06-01-02"   // headings above a task's entries
+	longDate           = "2006-01-02" // day lists, recent boxes and other screens
+)
+
+// sourceLabels gives the short name of each notes source, by source name,
+// for headings to show where entries come from. It is empty when there is
+// only one source.
+var sourceLabels = map[string]string{}
+
+// now is the clock every screen reads; tests pin it.
+var now = time.Now
+
+// currentDay is today's date in the form entry dates take: midnight UTC.
+func currentDay() time.Time {
+	return now().UTC().Truncate(24 * time.Hour)
 }
 
 func defaultText(text string) *tview.TextView {
@@ -55,22 +60,10 @@ func defaultText(text string) *tview.TextView {
 func renderDaySummary(date time.Time, data *TrailData) string {
 	var sb strings.Builder
 
-	projectNames := make([]string, 0, len(data.Projects))
-	for name := range data.Projects {
-		projectNames = append(projectNames, name)
-	}
-	sort.Strings(projectNames)
-
-	for _, projectName := range projectNames {
+	for _, projectName := range data.ProjectNames() {
 		project := data.Projects[projectName]
-		taskNames := make([]string, 0, len(project.Tasks))
-		for name := range project.Tasks {
-			taskNames = append(taskNames, name)
-		}
-		sort.Strings(taskNames)
-
 		var projectLines strings.Builder
-		for _, taskName := range taskNames {
+		for _, taskName := range project.TaskNames() {
 			entries := project.Tasks[taskName]
 			var taskLines strings.Builder
 			for _, entry := range entries {
@@ -96,8 +89,75 @@ func renderDaySummary(date time.Time, data *TrailData) string {
 	return result
 }
 
+// renderEntries lists entries under a date heading each time the date
+// changes. Entries are expected to be sorted newest first.
+func renderEntries(entries []notes.Entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	heading := func(entry notes.Entry) string {
+		parts := []string{entry.Date.Format(shortDate)}
+		if label := sourceLabels[entry.Source]; label != "" {
+			parts = append(parts, label)
+		}
+		if entry.Author != "" {
+			parts = append(parts, entry.Author)
+		}
+		if len(entry.Tags) > 0 {
+			parts = append(parts, "#"+strings.Join(entry.Tags, " #"))
+		}
+		return strings.Join(parts, " · ")
+	}
+	current := entries[0]
+	text := heading(current)
+	for _, entry := range entries {
+		if entry.Date != current.Date || entry.Source != current.Source || entry.Author != current.Author ||
+			!slices.Equal(entry.Tags, current.Tags) {
+			current = entry
+			text += "\n" + heading(current)
+		}
+		text += "\n" + entry.Content
+	}
+	return text
+}
 
-var screenNames = []string{"projects", "tasks", "days", "recent"}
+// renderProjectSummary lists a project's tasks with how many entries each
+// has, when it was last touched and by whom.
+func renderProjectSummary(project notes.Project) string {
+	taskNames := project.TaskNames()
+	width := 0
+	for _, name := range taskNames {
+		width = max(width, len(name))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@%s\n", project.Name)
+	for _, name := range taskNames {
+		entries := project.Tasks[name]
+		fmt.Fprintf(&sb, "  +%-*s  %d %s", width, name, len(entries), plural(len(entries), "entry", "entries"))
+		if last := notes.LastDate(entries); !last.IsZero() {
+			fmt.Fprintf(&sb, ", last %s", last.Format(shortDate))
+		}
+		if authors := entryAuthors(entries); len(authors) > 0 {
+			fmt.Fprintf(&sb, ", by %s", strings.Join(authors, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// entryAuthors lists who wrote entries, most recent first.
+func entryAuthors(entries []notes.Entry) []string {
+	var authors []string
+	for _, entry := range notes.NewestFirst(entries) {
+		if entry.Author != "" && !slices.Contains(authors, entry.Author) {
+			authors = append(authors, entry.Author)
+		}
+	}
+	return authors
+}
+
+var screenNames = []string{"pinned", "projects", "tasks", "days", "week", "calendar", "recent", "timeline", "stats", "authors"}
 
 func switchScreen(pages *tview.Pages, current *string, direction int) {
 	idx := 0
@@ -121,9 +181,13 @@ type ProjectsScreen struct {
 	list           *tview.List
 	taskList       *tview.List
 	taskContent    *tview.TextView
+	split          *splitView
 	data           *TrailData
 	app            *tview.Application
-	currentProject *Project
+	currentProject *notes.Project
+	projects       []notes.Project // list items, in display order
+	taskNames      []string        // taskList items, in display order
+	taskEntries    [][]notes.Entry // taskList items, in display order
 }
 
 func newProjectsScreen(data *TrailData, app *tview.Application) *ProjectsScreen {
@@ -140,18 +204,26 @@ func newProjectsScreen(data *TrailData, app *tview.Application) *ProjectsScreen
 		}
 	})
 
-	ps.list = vimList(tview.NewList())
-	ps.taskList = vimList(tview.NewList())
+	ps.list = tview.NewList()
+	ps.taskList = tview.NewList()
 	ps.taskContent = tview.NewTextView().SetScrollable(true)
 
 	ps.innerPages = tview.NewPages()
 	ps.innerPages.AddPage("list", ps.list, true, true)
 	ps.innerPages.AddPage("tasks", ps.taskList, true, false)
 	ps.innerPages.AddPage("content", ps.taskContent, true, false)
+	ps.split = newSplitView(ps.innerPages)
+
+	ps.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		ps.previewProject(index)
+	})
+	ps.taskList.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		ps.previewTask(index)
+	})
 
 	ps.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
 	ps.Root.AddItem(ps.filter, 0, 0, 1, 1, 0, 0, false)
-	ps.Root.AddItem(ps.innerPages, 1, 0, 1, 1, 0, 0, true)
+	ps.Root.AddItem(ps.split, 1, 0, 1, 1, 0, 0, true)
 
 	ps.populateProjects("")
 	return ps
@@ -159,67 +231,105 @@ func newProjectsScreen(data *TrailData, app *tview.Application) *ProjectsScreen
 
 func (ps *ProjectsScreen) populateProjects(filter string) {
 	ps.list.Clear()
-	names := make([]string, 0, len(ps.data.Projects))
-	for name := range ps.data.Projects {
-		if filter == "" || strings.Contains(name, filter) {
-			names = append(names, name)
+	items := make([]fuzzyItem, 0, len(ps.data.Projects))
+	for name, project := range ps.data.Projects {
+		score, positions, ok := fuzzyMatch(filter, name)
+		if !ok {
+			continue
 		}
+		var last time.Time
+		for _, entries := range project.Tasks {
+			if d := notes.LastDate(entries); d.After(last) {
+				last = d
+			}
+		}
+		items = append(items, fuzzyItem{label: name, score: score, positions: positions, lastEntry: last})
 	}
-	sort.Strings(names)
-	for _, name := range names {
-		p := ps.data.Projects[name]
-		ps.list.AddItem(p.Name, "", 0, func() {
+	rankFuzzyItems(items, filter)
+	slices.SortStableFunc(items, func(a, b fuzzyItem) int {
+		return comparePinnedProjects(a.label, b.label)
+	})
+	ps.projects = ps.projects[:0]
+	for _, item := range items {
+		p := ps.data.Projects[item.label]
+		ps.projects = append(ps.projects, p)
+		ps.list.AddItem(pinMarker(pins.hasProject(p.Name))+highlightMatches(p.Name, item.positions), "", 0, func() {
 			ps.showTasks(p)
 		})
 	}
+	ps.previewProject(ps.list.GetCurrentItem())
 }
 
-func (ps *ProjectsScreen) showTasks(project Project) {
+func (ps *ProjectsScreen) previewProject(index int) {
+	if index < 0 || index >= len(ps.projects) {
+		ps.split.show("")
+		return
+	}
+	ps.split.show(renderProjectSummary(ps.projects[index]))
+}
+
+func (ps *ProjectsScreen) previewTask(index int) {
+	if index < 0 || index >= len(ps.taskEntries) {
+		ps.split.show("")
+		return
+	}
+	ps.split.show(renderEntries(ps.taskEntries[index]))
+}
+
+func (ps *ProjectsScreen) showTasks(project notes.Project) {
 	ps.currentProject = &project
 	ps.taskList.Clear()
+	ps.taskEntries = ps.taskEntries[:0]
 
-	taskNames := make([]string, 0, len(project.Tasks))
-	for name := range project.Tasks {
-		taskNames = append(taskNames, name)
-	}
-	sort.Strings(taskNames)
+	ps.taskNames = project.TaskNames()
+	slices.SortStableFunc(ps.taskNames, func(a, b string) int {
+		return comparePinned(pinKey(project.Name, a), pinKey(project.Name, b))
+	})
 
-	for _, name := range taskNames {
-		entries := slices.Clone(project.Tasks[name])
-		slices.SortStableFunc(entries, func(a, b Entry) int {
-			return b.Date.Compare(a.Date)
-		})
-		ps.taskList.AddItem(name, "", 0, func() {
+	for _, name := range ps.taskNames {
+		entries := notes.NewestFirst(project.Tasks[name])
+		ps.taskEntries = append(ps.taskEntries, entries)
+		ps.taskList.AddItem(pinMarker(pins.has(project.Name, name))+tview.Escape(name), "", 0, func() {
 			ps.showTaskContent(entries)
 		})
 	}
+	ps.taskList.SetCurrentItem(0)
+	ps.previewTask(0)
 	ps.innerPages.SwitchToPage("tasks")
 	ps.app.SetFocus(ps.taskList)
 }
 
-func (ps *ProjectsScreen) showTaskContent(entries []Entry) {
-	if len(entries) == 0 {
-		ps.taskContent.SetText("")
-	} else {
-		currentDate := entries[0].Date
-		text := currentDate.Format("06-01-02")
-		for _, entry := range entries {
-			if entry.Date != currentDate {
-				currentDate = entry.Date
-				text += "\n" + currentDate.Format("06-01-02")
-			}
-			text += "\n" + entry.Content
+// openProject clears the filter and shows the named project's tasks.
+func (ps *ProjectsScreen) openProject(name string) {
+	ps.filter.SetText("")
+	for i, project := range ps.projects {
+		if project.Name == name {
+			ps.list.SetCurrentItem(i)
+			ps.showTasks(project)
+			return
 		}
-		ps.taskContent.SetText(text)
 	}
+}
+
+func (ps *ProjectsScreen) showTaskContent(entries []notes.Entry) {
+	if ps.split.active {
+		ps.split.show(renderEntries(entries))
+		ps.app.SetFocus(ps.split.preview)
+		return
+	}
+	ps.taskContent.SetText(renderEntries(entries))
 	ps.innerPages.SwitchToPage("content")
 	ps.app.SetFocus(ps.taskContent)
 }
 
 func (ps *ProjectsScreen) handleEsc() {
-	if ps.app.GetFocus() == ps.filter {
+	switch ps.app.GetFocus() {
+	case ps.filter:
 		ps.app.SetFocus(ps.list)
 		return
+	case ps.split.preview:
+		ps.app.SetFocus(ps.innerPages)
+		return
 	}
 	name, _ := ps.innerPages.GetFrontPage()
 	switch name {
@@ -230,6 +340,7 @@ func (ps *ProjectsScreen) handleEsc() {
 		ps.currentProject = nil
 		ps.innerPages.SwitchToPage("list")
 		ps.app.SetFocus(ps.list)
+		ps.previewProject(ps.list.GetCurrentItem())
 	}
 }
 
@@ -237,6 +348,82 @@ func (ps *ProjectsScreen) focusFilter() {
 	ps.app.SetFocus(ps.filter)
 }
 
+// selectedTask is the highlighted or open task once a project has been
+// selected.
+func (ps *ProjectsScreen) selectedTask() (project, task string) {
+	index := ps.taskList.GetCurrentItem()
+	if ps.currentProject == nil || index < 0 || index >= len(ps.taskNames) {
+		return "", ""
+	}
+	return ps.currentProject.Name, ps.taskNames[index]
+}
+
+// refresh rebuilds the lists after the notes or the pins change, keeping
+// the filter, the selections and the page that is showing. If the open
+// project is gone it goes back to the project list.
+func (ps *ProjectsScreen) refresh() {
+	var highlighted string
+	if index := ps.list.GetCurrentItem(); index >= 0 && index < len(ps.projects) {
+		highlighted = ps.projects[index].Name
+	}
+	_, task := ps.selectedTask()
+	page, _ := ps.innerPages.GetFrontPage()
+	focus := ps.app.GetFocus()
+
+	ps.populateProjects(ps.filter.GetText())
+	if i := slices.IndexFunc(ps.projects, func(p notes.Project) bool { return p.Name == highlighted }); i >= 0 {
+		ps.list.SetCurrentItem(i)
+	}
+	if ps.currentProject == nil {
+		return
+	}
+	open, ok := ps.data.Projects[ps.currentProject.Name]
+	if !ok {
+		ps.currentProject = nil
+		ps.innerPages.SwitchToPage("list")
+		if focus == ps.taskList || focus == ps.taskContent {
+			ps.app.SetFocus(ps.list)
+		}
+		return
+	}
+	ps.showTasks(open)
+	if i := slices.Index(ps.taskNames, task); i >= 0 {
+		ps.taskList.SetCurrentItem(i)
+		if page == "content" {
+			ps.taskContent.SetText(renderEntries(ps.taskEntries[i]))
+		}
+	} else if page == "content" {
+		page = "tasks"
+		if focus == ps.taskContent {
+			focus = ps.taskList
+		}
+	}
+	ps.innerPages.SwitchToPage(page)
+	ps.app.SetFocus(focus)
+}
+
+func (ps *ProjectsScreen) restyle() {
+	restyle(ps.Root, ps.filter, ps.split)
+}
+
+// yankText is the highlighted project, or the highlighted or open task once
+// a project has been selected.
+func (ps *ProjectsScreen) yankText() (text, what string) {
+	if ps.currentProject != nil {
+		project, task := ps.selectedTask()
+		if task == "" {
+			return "", ""
+		}
+		index := ps.taskList.GetCurrentItem()
+		return markdownTask(project, task, ps.taskEntries[index]), "@" + project + " +" + task
+	}
+	index := ps.list.GetCurrentItem()
+	if index < 0 || index >= len(ps.projects) {
+		return "", ""
+	}
+	return markdownProject(ps.projects[index]), "@" + ps.projects[index].Name
+}
+
 // --- TasksScreen ---
 
 type TasksScreen struct {
@@ -245,8 +432,11 @@ type TasksScreen struct {
 	filter     *tview.InputField
 	list       *tview.List
 	content    *tview.TextView
+	split      *splitView
 	data       *TrailData
 	app        *tview.Application
+	entries    [][]notes.Entry // list items, in display order
+	labels     []string        // "project/task" of each list item
 }
 
 func newTasksScreen(data *TrailData, app *tview.Application) *TasksScreen {
@@ -263,16 +453,21 @@ func newTasksScreen(data *TrailData, app *tview.Application) *TasksScreen {
 		}
 	})
 
-	ts.list = vimList(tview.NewList())
+	ts.list = tview.NewList()
 	ts.content = tview.NewTextView().SetScrollable(true)
 
 	ts.innerPages = tview.NewPages()
 	ts.innerPages.AddPage("list", ts.list, true, true)
 	ts.innerPages.AddPage("content", ts.content, true, false)
+	ts.split = newSplitView(ts.innerPages)
+
+	ts.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		ts.preview(index)
+	})
 
 	ts.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
 	ts.Root.AddItem(ts.filter, 0, 0, 1, 1, 0, 0, false)
-	ts.Root.AddItem(ts.innerPages, 1, 0, 1, 1, 0, 0, true)
+	ts.Root.AddItem(ts.split, 1, 0, 1, 1, 0, 0, true)
 
 	ts.populateTasks("")
 	return ts
@@ -281,57 +476,69 @@ func newTasksScreen(data *TrailData, app *tview.Application) *TasksScreen {
 func (ts *TasksScreen) populateTasks(filter string) {
 	ts.list.Clear()
 
-	type taskItem struct {
-		label   string
-		entries []Entry
-	}
-
-	var items []taskItem
+	taskEntries := make(map[string][]notes.Entry)
+	var items []fuzzyItem
 	for _, project := range ts.data.Projects {
 		for taskName, entries := range project.Tasks {
 			label := project.Name + "/" + taskName
-			if filter == "" || strings.Contains(label, filter) {
-				items = append(items, taskItem{label: label, entries: entries})
+			score, positions, ok := fuzzyMatch(filter, label)
+			if !ok {
+				continue
 			}
+			taskEntries[label] = entries
+			items = append(items, fuzzyItem{label: label, score: score, positions: positions, lastEntry: notes.LastDate(entries)})
 		}
 	}
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].label < items[j].label
+	rankFuzzyItems(items, filter)
+	slices.SortStableFunc(items, func(a, b fuzzyItem) int {
+		return comparePinned(a.label, b.label)
 	})
 
+	ts.entries = ts.entries[:0]
+	ts.labels = ts.labels[:0]
 	for _, item := range items {
-		entries := slices.Clone(item.entries)
-		slices.SortStableFunc(entries, func(a, b Entry) int {
-			return b.Date.Compare(a.Date)
-		})
-		label := item.label
-		ts.list.AddItem(label, "", 0, func() {
+		entries := notes.NewestFirst(taskEntries[item.label])
+		ts.entries = append(ts.entries, entries)
+		ts.labels = append(ts.labels, item.label)
+		marker := pinMarker(slices.Contains(pins.keys, item.label))
+		ts.list.AddItem(marker+highlightMatches(item.label, item.positions), "", 0, func() {
 			ts.showContent(entries)
 		})
 	}
+	ts.preview(ts.list.GetCurrentItem())
 }
 
-func (ts *TasksScreen) showContent(entries []Entry) {
-	if len(entries) == 0 {
-		ts.content.SetText("")
-	} else {
-		currentDate := entries[0].Date
-		text := currentDate.Format("06-01-02")
-		for _, entry := range entries {
-			if entry.Date != currentDate {
-				currentDate = entry.Date
-				text += "\n" + currentDate.Format("06-01-02")
-			}
-			text += "\n" + entry.Content
-		}
-		ts.content.SetText(text)
+func (ts *TasksScreen) preview(index int) {
+	if index < 0 || index >= len(ts.entries) {
+		ts.split.show("")
+		return
+	}
+	ts.split.show(renderEntries(ts.entries[index]))
+}
+
+func (ts *TasksScreen) showContent(entries []notes.Entry) {
+	if ts.split.active {
+		ts.split.show(renderEntries(entries))
+		ts.app.SetFocus(ts.split.preview)
+		return
 	}
+	ts.content.SetText(renderEntries(entries))
 	ts.innerPages.SwitchToPage("content")
 	ts.app.SetFocus(ts.content)
 }
 
+// openTask clears the filter and shows the entries of project/task.
+func (ts *TasksScreen) openTask(project, task string) {
+	ts.filter.SetText("")
+	if i := slices.Index(ts.labels, project+"/"+task); i >= 0 {
+		ts.list.SetCurrentItem(i)
+		ts.showContent(ts.entries[i])
+	}
+}
+
 func (ts *TasksScreen) handleEsc() {
-	if ts.app.GetFocus() == ts.filter {
+	switch ts.app.GetFocus() {
+	case ts.filter, ts.split.preview:
 		ts.app.SetFocus(ts.list)
 		return
 	}
@@ -346,6 +553,53 @@ func (ts *TasksScreen) focusFilter() {
 	ts.app.SetFocus(ts.filter)
 }
 
+// selectedTask is the highlighted or open task, if any.
+func (ts *TasksScreen) selectedTask() (project, task string) {
+	index := ts.list.GetCurrentItem()
+	if index < 0 || index >= len(ts.labels) {
+		return "", ""
+	}
+	project, task, _ = strings.Cut(ts.labels[index], "/")
+	return project, task
+}
+
+// refresh rebuilds the list after the notes or the pins change, keeping the
+// filter, the selection and the open task. If that task is gone it goes
+// back to the list.
+func (ts *TasksScreen) refresh() {
+	project, task := ts.selectedTask()
+	ts.populateTasks(ts.filter.GetText())
+	i := slices.Index(ts.labels, pinKey(project, task))
+	if i >= 0 {
+		ts.list.SetCurrentItem(i)
+	}
+	if name, _ := ts.innerPages.GetFrontPage(); name != "content" {
+		return
+	}
+	if i >= 0 {
+		ts.content.SetText(renderEntries(ts.entries[i]))
+		return
+	}
+	ts.innerPages.SwitchToPage("list")
+	if ts.app.GetFocus() == ts.content {
+		ts.app.SetFocus(ts.list)
+	}
+}
+
+func (ts *TasksScreen) restyle() {
+	restyle(ts.Root, ts.filter, ts.split)
+}
+
+// yankText is the history of the highlighted or open task.
+func (ts *TasksScreen) yankText() (text, what string) {
+	index := ts.list.GetCurrentItem()
+	if index < 0 || index >= len(ts.entries) {
+		return "", ""
+	}
+	project, task := ts.selectedTask()
+	return markdownTask(project, task, ts.entries[index]), "@" + project + " +" + task
+}
+
 // --- DaysScreen ---
 
 type DaysScreen struct {
@@ -354,8 +608,11 @@ type DaysScreen struct {
 	filter     *tview.InputField
 	list       *tview.List
 	detail     *tview.TextView
+	split      *splitView
 	data       *TrailData
 	app        *tview.Application
+	dates      []time.Time // list items, in display order
+	shown      time.Time   // date on the detail page
 }
 
 func newDaysScreen(data *TrailData, app *tview.Application) *DaysScreen {
@@ -372,16 +629,21 @@ func newDaysScreen(data *TrailData, app *tview.Application) *DaysScreen {
 		}
 	})
 
-	ds.list = vimList(tview.NewList())
+	ds.list = tview.NewList()
 	ds.detail = tview.NewTextView().SetScrollable(true)
 
 	ds.innerPages = tview.NewPages()
 	ds.innerPages.AddPage("list", ds.list, true, true)
 	ds.innerPages.AddPage("detail", ds.detail, true, false)
+	ds.split = newSplitView(ds.innerPages)
+
+	ds.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		ds.preview(index)
+	})
 
 	ds.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
 	ds.Root.AddItem(ds.filter, 0, 0, 1, 1, 0, 0, false)
-	ds.Root.AddItem(ds.innerPages, 1, 0, 1, 1, 0, 0, true)
+	ds.Root.AddItem(ds.split, 1, 0, 1, 1, 0, 0, true)
 
 	ds.populateDays("")
 	return ds
@@ -390,44 +652,72 @@ func newDaysScreen(data *TrailData, app *tview.Application) *DaysScreen {
 func (ds *DaysScreen) populateDays(filter string) {
 	ds.list.Clear()
 
-	dateSet := make(map[time.Time]struct{})
-	for _, project := range ds.data.Projects {
-		for _, entries := range project.Tasks {
-			for _, entry := range entries {
-				dateSet[entry.Date] = struct{}{}
-			}
-		}
-	}
-
-	dates := make([]time.Time, 0, len(dateSet))
-	for d := range dateSet {
-		dates = append(dates, d)
-	}
-	sort.Slice(dates, func(i, j int) bool {
-		return dates[i].After(dates[j])
-	})
+	dates := ds.data.Dates()
+	slices.Reverse(dates)
 
+	ds.dates = ds.dates[:0]
 	for _, date := range dates {
-		label := date.Format("2006-01-02")
+		label := date.Format(longDate)
 		if filter != "" && !strings.Contains(label, filter) {
 			continue
 		}
 		d := date
+		ds.dates = append(ds.dates, d)
 		ds.list.AddItem(label, "", 0, func() {
 			ds.showDetail(d)
 		})
 	}
+	ds.preview(ds.list.GetCurrentItem())
+}
+
+func (ds *DaysScreen) preview(index int) {
+	if index < 0 || index >= len(ds.dates) {
+		ds.split.show("")
+		return
+	}
+	ds.split.show(renderDaySummary(ds.dates[index], ds.data))
 }
 
 func (ds *DaysScreen) showDetail(date time.Time) {
 	summary := renderDaySummary(date, ds.data)
+	if ds.split.active {
+		// Keep the list in step when the date comes from another screen.
+		if i := slices.IndexFunc(ds.dates, date.Equal); i >= 0 {
+			ds.list.SetCurrentItem(i)
+		}
+		ds.split.show(summary)
+		ds.app.SetFocus(ds.split.preview)
+		return
+	}
+	ds.shown = date
 	ds.detail.SetText(summary)
 	ds.innerPages.SwitchToPage("detail")
 	ds.app.SetFocus(ds.detail)
 }
 
+// refresh re-reads the notes, keeping the filter, the selection and the day
+// on the detail page.
+func (ds *DaysScreen) refresh() {
+	var highlighted time.Time
+	if index := ds.list.GetCurrentItem(); index >= 0 && index < len(ds.dates) {
+		highlighted = ds.dates[index]
+	}
+	ds.populateDays(ds.filter.GetText())
+	if i := slices.IndexFunc(ds.dates, highlighted.Equal); i >= 0 {
+		ds.list.SetCurrentItem(i)
+	}
+	if name, _ := ds.innerPages.GetFrontPage(); name == "detail" {
+		ds.detail.SetText(renderDaySummary(ds.shown, ds.data))
+	}
+}
+
+func (ds *DaysScreen) restyle() {
+	restyle(ds.Root, ds.filter, ds.split)
+}
+
 func (ds *DaysScreen) handleEsc() {
-	if ds.app.GetFocus() == ds.filter {
+	switch ds.app.GetFocus() {
+	case ds.filter, ds.split.preview:
 		ds.app.SetFocus(ds.list)
 		return
 	}
@@ -442,164 +732,127 @@ func (ds *DaysScreen) focusFilter() {
 	ds.app.SetFocus(ds.filter)
 }
 
-// --- RecentScreen ---
-
-type RecentScreen struct {
-	Root      *tview.Grid
-	days      *tview.InputField
-	content   *tview.TextView
-	data      *TrailData
-	app       *tview.Application
-	lastWidth int
-	lastN     int
-}
-
-// renderRecentBoxes renders project/task boxes sized to width (the content
-// area column count, i.e. terminal width minus the surrounding grid borders).
-// Each line is padded so right-border characters land on the last column.
-func renderRecentBoxes(days int, data *TrailData, width int) string {
-	if days <= 0 || width <= 7 {
-		return ""
-	}
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	cutoff := today.AddDate(0, 0, -(days - 1))
-
-	projectNames := make([]string, 0, len(data.Projects))
-	for name := range data.Projects {
-		projectNames = append(projectNames, name)
+// yankText is the summary of the open or highlighted day.
+func (ds *DaysScreen) yankText() (text, what string) {
+	date := ds.shown
+	if name, _ := ds.innerPages.GetFrontPage(); name != "detail" {
+		index := ds.list.GetCurrentItem()
+		if index < 0 || index >= len(ds.dates) {
+			return "", ""
+		}
+		date = ds.dates[index]
 	}
-	sort.Strings(projectNames)
-
-	const blue = "[#7aa2f7]"
-	const orange = "[#e0af68]"
-	const reset = "[-]"
+	return markdownDay(date, ds.data), date.Format(longDate)
+}
 
-	var sb strings.Builder
+// --- Main ---
 
-	for _, projectName := range projectNames {
-		project := data.Projects[projectName]
-		taskNames := make([]string, 0, len(project.Tasks))
-		for name := range project.Tasks {
-			taskNames = append(taskNames, name)
+// noteSource is somewhere notes are read from: a notes directory, or the
+// archive passed with --archive.
+type noteSource struct {
+	name     string // path shown in the status line and diagnostics
+	label    string // short name shown next to its entries
+	fsys     fs.FS
+	readOnly bool // archives can't be edited
+}
+
+// parseCache keeps what each note parsed to across runs. main opens it in
+// the state directory; while it is nil every file is parsed.
+var parseCache *notes.Cache
+
+// loadTrailData parses every source, merging projects and tasks that appear
+// in more than one. With separate_projects set, a project found in several
+// sources is kept apart instead, as label:project for each of them. It
+// fails if a source can't be read.
+func loadTrailData(cfg *Config) (TrailData, error) {
+	sources := cfg.sources
+	data := TrailData{Notes: *notes.New(), LoadedAt: now(), bySource: make(map[string]*notes.Notes)}
+	parsed := make([]*notes.Notes, len(sources))
+	seen := make(map[string]int) // sources each project is in
+	for i, source := range sources {
+		parser := notes.Parser{
+			DateLayout:  filenameDateLayout,
+			AuthorDirs:  cfg.AuthorDirs,
+			DefaultTask: cfg.DefaultTask,
+			Cache:       parseCache,
+			Source:      source.name,
 		}
-		sort.Strings(taskNames)
-
-		var taskBlocks strings.Builder
-		hasContent := false
-		first := true
-
-		for _, taskName := range taskNames {
-			dateMap := make(map[time.Time][]string)
-			for _, entry := range project.Tasks[taskName] {
-				if !entry.Date.Before(cutoff) && !entry.Date.After(today) {
-					dateMap[entry.Date] = append(dateMap[entry.Date], entry.Content)
-				}
-			}
-			if len(dateMap) == 0 {
-				continue
-			}
-
-			dates := make([]time.Time, 0, len(dateMap))
-			for d := range dateMap {
-				dates = append(dates, d)
-			}
-			sort.Slice(dates, func(i, j int) bool {
-				return dates[i].After(dates[j])
-			})
-
-			taskTitle := " +" + taskName + " "
-			if !first {
-				// gap line: " │" + spaces + "│ "  (width = 2 + n + 2)
-				fmt.Fprintf(&taskBlocks, " %s│%s%s%s│%s \n", blue, reset, strings.Repeat(" ", max(0, width-4)), blue, reset)
-			}
-			// inner top: " │ ┌ " + "+taskName " + "─"×n + "┐ │ "  (width = 5 + (len-1) + n + 4)
-			fmt.Fprintf(&taskBlocks, " %s│ ┌ %s+%s %s%s┐ │%s \n", blue, orange, taskName, blue, strings.Repeat("─", max(0, width-8-len(taskTitle))), reset)
-			for _, date := range dates {
-				dateStr := date.Format("2006-01-02") // always 10 chars
-				// date line: " │ │ " + date + spaces + "│ │ "  (width = 5 + 10 + n + 4)
-				fmt.Fprintf(&taskBlocks, " %s│ │%s %s%s%s│ │%s \n", blue, reset, dateStr, strings.Repeat(" ", max(0, width-19)), blue, reset)
-				for _, content := range dateMap[date] {
-					cw := len([]rune(content))
-					// content line: " │ │  " + content + spaces + "│ │ "  (width = 6 + cw + n + 4)
-					fmt.Fprintf(&taskBlocks, " %s│ │%s  %s%s%s│ │%s \n", blue, reset, content, strings.Repeat(" ", max(0, width-10-cw)), blue, reset)
-				}
-			}
-			// inner bottom: " │ └" + "─"×n + "┘ │ "  (width = 4 + n + 4)
-			fmt.Fprintf(&taskBlocks, " %s│ └%s┘ │%s \n", blue, strings.Repeat("─", max(0, width-8)), reset)
-			hasContent = true
-			first = false
+		sourceNotes, diagnostics, err := parser.Parse(source.fsys)
+		if err != nil {
+			return TrailData{}, fmt.Errorf("%s: %w", source.name, err)
 		}
-
-		if !hasContent {
-			continue
+		for j, file := range sourceNotes.Files {
+			sourceNotes.Files[j] = filepath.Join(source.name, file)
 		}
-		projTitle := " @" + projectName + " "
-		// outer top: " ┌ @" + projectName + " " + "─"×n + "┐ "  (width = 2 + len + n + 2)
-		fmt.Fprintf(&sb, " %s┌ %s@%s %s%s┐%s \n", blue, orange, projectName, blue, strings.Repeat("─", max(0, width-4-len(projTitle))), reset)
-		sb.WriteString(taskBlocks.String())
-		// outer bottom: " └" + "─"×n + "┘ "  (width = 2 + n + 2)
-		fmt.Fprintf(&sb, " %s└%s┘%s \n", blue, strings.Repeat("─", max(0, width-4)), reset)
-		sb.WriteString("\n")
-	}
-	return strings.TrimRight(sb.String(), "\n")
-}
-
-func newRecentScreen(data *TrailData, app *tview.Application) *RecentScreen {
-	rs := &RecentScreen{data: data, app: app, lastN: 28}
-
-	rs.content = tview.NewTextView().SetScrollable(true).SetWrap(false).SetDynamicColors(true)
-
-	rs.days = tview.NewInputField().
-		SetLabel("Last N days: ").
-		SetText("28").
-		SetAcceptanceFunc(tview.InputFieldInteger).
-		SetChangedFunc(func(text string) {
-			n, err := strconv.Atoi(text)
-			if err != nil || n <= 0 {
-				rs.lastN = 0
-				rs.content.SetText("")
-				return
-			}
-			rs.lastN = n
-			rs.content.SetText(renderRecentBoxes(n, data, rs.lastWidth))
-		})
-	rs.days.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
-			app.SetFocus(rs.content)
+		for name := range sourceNotes.Projects {
+			seen[name]++
 		}
-	})
-
-	// Re-render whenever the terminal is resized.
-	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
-		w, _ := screen.Size()
-		cw := w - 2 // subtract left+right grid border
-		if cw != rs.lastWidth {
-			rs.lastWidth = cw
-			if rs.lastN > 0 {
-				rs.content.SetText(renderRecentBoxes(rs.lastN, data, cw))
+		parsed[i] = sourceNotes
+		for _, d := range diagnostics {
+			d.File = filepath.Join(source.name, d.File)
+			log.Println(d)
+			data.Diagnostics = append(data.Diagnostics, d)
+		}
+	}
+	for i, sourceNotes := range parsed {
+		if cfg.SeparateProjects {
+			for _, name := range sourceNotes.ProjectNames() {
+				if seen[name] > 1 {
+					sourceNotes.RenameProject(name, sources[i].label+":"+name)
+				}
 			}
 		}
-		return false
-	})
-
-	rs.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
-	rs.Root.AddItem(rs.days, 0, 0, 1, 1, 0, 0, false)
-	rs.Root.AddItem(rs.content, 1, 0, 1, 1, 0, 0, true)
+		data.Merge(sourceNotes)
+		data.bySource[sources[i].name] = sourceNotes
+	}
+	if err := parseCache.Save(); err != nil {
+		log.Println("parse cache:", err)
+	}
+	return data, nil
+}
 
-	return rs
+// filterAuthor narrows data down to one person's entries, and the files
+// they came from.
+func filterAuthor(data TrailData, author string) TrailData {
+	filtered := data
+	filtered.Notes = *data.Filter(func(entry notes.Entry) bool { return entry.Author == author })
+	files := make(map[string]bool)
+	for _, entry := range filtered.Entries() {
+		files[filepath.Join(entry.Source, filepath.FromSlash(entry.File))] = true
+	}
+	filtered.Files = slices.DeleteFunc(filtered.Files, func(file string) bool { return !files[file] })
+	return filtered
 }
 
-func (rs *RecentScreen) handleEsc() {
-	if rs.app.GetFocus() == rs.days {
-		rs.app.SetFocus(rs.content)
+// setSourceLabels fills in sourceLabels when there is more than one source
+// to tell apart.
+func setSourceLabels(sources []noteSource) {
+	sourceLabels = map[string]string{}
+	if len(sources) > 1 {
+		for _, source := range sources {
+			sourceLabels[source.name] = source.label
+		}
 	}
 }
 
-func (rs *RecentScreen) focusFilter() {
-	rs.app.SetFocus(rs.days)
+// filterSource narrows data down to the notes read from one source. Its
+// projects and tasks are those the source's files name, entries or not, as
+// they are when every source is shown.
+func filterSource(data TrailData, source noteSource) TrailData {
+	filtered := data
+	filtered.Notes = *data.bySource[source.name]
+	filtered.Files = slices.Clone(filtered.Files)
+	filtered.Diagnostics = slices.DeleteFunc(slices.Clone(data.Diagnostics), func(d notes.Diagnostic) bool {
+		return !inDir(d.File, source.name)
+	})
+	return filtered
 }
 
-// --- Main ---
+// inDir reports whether path is inside dir, however either is written.
+func inDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
 
 func main() {
 	stateDir := os.Getenv("XDG_STATE_HOME")
@@ -610,11 +863,11 @@ func main() {
 		}
 		stateDir = filepath.Join(home, ".local", "state")
 	}
-	logDir := filepath.Join(stateDir, "trail")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
+	trailStateDir := filepath.Join(stateDir, "trail")
+	if err := os.MkdirAll(trailStateDir, 0755); err != nil {
 		panic(err)
 	}
-	logPath := filepath.Join(logDir, "trail.log")
+	logPath := filepath.Join(trailStateDir, "trail.log")
 	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		panic(err)
@@ -623,83 +876,73 @@ func main() {
 	log.SetOutput(logFile)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	tview.Styles = tview.Theme{
-		PrimitiveBackgroundColor:    tcell.NewRGBColor(26, 27, 38),   // #1a1b26 — background
-		ContrastBackgroundColor:     tcell.NewRGBColor(22, 22, 30),   // #16161e — input field bg
-		MoreContrastBackgroundColor: tcell.NewRGBColor(65, 72, 104),  // #414868 — autocomplete bg
-		BorderColor:                 tcell.NewRGBColor(65, 72, 104),  // #414868 — borders
-		TitleColor:                  tcell.NewRGBColor(122, 162, 247),// #7aa2f7 — blue, box titles
-		GraphicsColor:               tcell.NewRGBColor(65, 72, 104),  // #414868 — graphics
-		PrimaryTextColor:            tcell.NewRGBColor(169, 177, 214),// #a9b1d6 — foreground
-		SecondaryTextColor:          tcell.NewRGBColor(122, 162, 247),// #7aa2f7 — blue labels
-		TertiaryTextColor:           tcell.NewRGBColor(120, 124, 153),// #787c99 — dimmed
-		InverseTextColor:            tcell.NewRGBColor(26, 27, 38),   // #1a1b26 — bg for inverse
-		ContrastSecondaryTextColor:  tcell.NewRGBColor(169, 177, 214),// #a9b1d6 — text in input fields
-	}
-
-	app := tview.NewApplication()
-
-	notesDir, err := os.Getwd()
+	configPath, err := defaultConfigPath()
 	if err != nil {
 		panic(err)
 	}
+	flag.StringVar(&configPath, "config", configPath, "path to config.toml")
+	archive := flag.String("archive", "", "browse a .zip, .tar or .tar.gz of notes, or a git tree as repo@rev, instead of the notes dirs")
+	var notesDirs []string
+	flag.Func("notes", "read notes from `dir` instead of notes_dirs; repeat for several", func(dir string) error {
+		notesDirs = append(notesDirs, dir)
+		return nil
+	})
+	flag.Parse()
+	explicitConfig := false
+	flag.Visit(func(f *flag.Flag) {
+		explicitConfig = explicitConfig || f.Name == "config"
+	})
+	cfg, err := loadConfig(configPath, explicitConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "trail: %v\n", err)
+		os.Exit(1)
+	}
+	if len(notesDirs) > 0 {
+		cfg.NotesDirs = notesDirs
+		if err := cfg.resolveSources(); err != nil {
+			fmt.Fprintf(os.Stderr, "trail: --notes: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *archive != "" {
+		fsys, err := notes.OpenArchive(expandHome(*archive))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "trail: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.sources = []noteSource{{name: *archive, label: filepath.Base(*archive), fsys: fsys, readOnly: true}}
+	}
+	setSourceLabels(cfg.sources)
 
-	projects := ProjectsFromDirectory(notesDir)
-	trailData := TrailData{Projects: projects}
-
-	rootPages := tview.NewPages()
-	currentScreen := "projects"
-
-	ps := newProjectsScreen(&trailData, app)
-	ts := newTasksScreen(&trailData, app)
-	ds := newDaysScreen(&trailData, app)
-	rs := newRecentScreen(&trailData, app)
-
-	rootPages.AddPage("projects", ps.Root, true, true)
-	rootPages.AddPage("tasks", ts.Root, true, false)
-	rootPages.AddPage("days", ds.Root, true, false)
-	rootPages.AddPage("recent", rs.Root, true, false)
-
-	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyTab:
-			switchScreen(rootPages, &currentScreen, 1)
-			return nil
-		case tcell.KeyBacktab:
-			switchScreen(rootPages, &currentScreen, -1)
-			return nil
-		case tcell.KeyEscape:
-			switch currentScreen {
-			case "projects":
-				ps.handleEsc()
-			case "tasks":
-				ts.handleEsc()
-			case "days":
-				ds.handleEsc()
-			case "recent":
-				rs.handleEsc()
-			}
-			return nil
-		}
-		if event.Rune() == '/' {
-			if _, ok := app.GetFocus().(*tview.InputField); !ok {
-				switch currentScreen {
-				case "projects":
-					ps.focusFilter()
-				case "tasks":
-					ts.focusFilter()
-				case "days":
-					ds.focusFilter()
-				case "recent":
-					rs.focusFilter()
-				}
-				return nil
-			}
+	applyTheme(cfg.theme)
+	filenameDateLayout = cfg.Dates.Filename
+	shortDate = cfg.Dates.Short
+	longDate = cfg.Dates.Long
+
+	if pins, err = loadPins(filepath.Join(trailStateDir, "pins.txt")); err != nil {
+		log.Println("pins:", err)
+	}
+	// With nothing configured, open on the pinned tasks if there are any.
+	if cfg.DefaultScreen == "" {
+		cfg.DefaultScreen = "projects"
+		if len(pins.keys) > 0 {
+			cfg.DefaultScreen = "pinned"
 		}
-		return event
-	})
+	}
+
+	if parseCache, err = notes.OpenCache(filepath.Join(trailStateDir, "cache.gob")); err != nil {
+		log.Println("parse cache:", err)
+	}
+	trailData, err := loadTrailData(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	hits, misses := parseCache.Stats()
+	log.Printf("parsed %d files, %d from the cache", hits+misses, hits)
 
-	if err := app.SetRoot(rootPages, true).SetFocus(rootPages).Run(); err != nil {
+	app := tview.NewApplication().EnableMouse(true)
+	newUI(app, &trailData, cfg)
+	if err := app.Run(); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,128 @@
+package notes
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// OpenArchive opens a zip, tar or tar.gz file of notes, or a git tree
+// written as repo@rev (any revision git accepts, including rev:subdir), as
+// an fs.FS to Parse. The archive is read into memory, so there is nothing
+// to close. If its top level holds a single directory and no notes, as
+// exported folders usually do, the FS starts inside that directory.
+func OpenArchive(name string) (fs.FS, error) {
+	var fsys fs.FS
+	var err error
+	if info, statErr := os.Stat(name); statErr == nil && !info.IsDir() {
+		fsys, err = openArchiveFile(name)
+	} else if repo, rev, ok := splitRepoRev(name); ok {
+		fsys, err = GitTree(repo, rev)
+	} else if statErr != nil {
+		return nil, statErr
+	} else {
+		return nil, fmt.Errorf("%s: not an archive (want .zip, .tar, .tar.gz or repo@rev)", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return notesRoot(fsys)
+}
+
+// splitRepoRev splits repo@rev at the last @ that follows a directory, so
+// the repository's path may hold an @ too (~/src/me@work/notes@main), and
+// so may the revision (main@{yesterday}).
+func splitRepoRev(name string) (repo, rev string, ok bool) {
+	for i := strings.LastIndex(name, "@"); i > 0; i = strings.LastIndex(name[:i], "@") {
+		if info, err := os.Stat(name[:i]); err == nil && info.IsDir() && i+1 < len(name) {
+			return name[:i], name[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+func openArchiveFile(name string) (fs.FS, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return TarFS(gz)
+	case strings.HasSuffix(lower, ".tar"):
+		return TarFS(bytes.NewReader(data))
+	}
+	return nil, errors.New("unknown archive type (want .zip, .tar, .tar.gz or .tgz)")
+}
+
+// TarFS reads the regular files of a tar stream into an in-memory FS.
+func TarFS(r io.Reader) (fs.FS, error) {
+	files := make(memFS)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = &memFile{data: data, mode: fs.FileMode(header.Mode).Perm(), modTime: header.ModTime}
+	}
+}
+
+// GitTree reads the tree at rev in the git repository repo, using
+// git archive.
+func GitTree(repo, rev string) (fs.FS, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", repo, "archive", "--format=tar", "--end-of-options", rev)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git archive: %s", msg)
+		}
+		return nil, fmt.Errorf("git archive: %w", err)
+	}
+	return TarFS(&stdout)
+}
+
+// notesRoot steps into the only directory at the top of fsys when there
+// are no notes beside it.
+func notesRoot(fsys fs.FS) (fs.FS, error) {
+	for {
+		dirEntries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		if len(dirEntries) != 1 || !dirEntries[0].IsDir() {
+			return fsys, nil
+		}
+		if fsys, err = fs.Sub(fsys, dirEntries[0].Name()); err != nil {
+			return nil, err
+		}
+	}
+}
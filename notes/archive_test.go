@@ -0,0 +1,250 @@
+package notes
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// archiveNotes is what the test archives hold: the notes in a single
+// exported folder, as a notes app would zip them up.
+var archiveNotes = map[string]string{
+	"export/25-02-13.md":       "@trail +archive\n- read zip files\n",
+	"export/25-02-14.md":       "@trail +archive\n- and tarballs\n",
+	"export/alice/25-02-14.md": "@garden +beds\n- dug\n",
+}
+
+func writeZip(t *testing.T, name string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range sortedKeys(archiveNotes) {
+		w, err := zw.Create(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(archiveNotes[file])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tarBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	modTime := time.Date(2025, time.February, 14, 18, 0, 0, 0, time.UTC)
+	// A directory entry and a symlink, which TarFS leaves out.
+	for _, header := range []*tar.Header{
+		{Name: "export/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "export/latest.md", Typeflag: tar.TypeSymlink, Linkname: "25-02-14.md"},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range sortedKeys(archiveNotes) {
+		data := archiveNotes[file]
+		header := &tar.Header{Name: "./" + file, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data)), ModTime: modTime}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTarGz(t *testing.T, name string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(tarBytes(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestOpenArchive(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name  string
+		write func(*testing.T, string)
+	}{
+		{"notes.zip", writeZip},
+		{"notes.tar.gz", writeTarGz},
+		{"notes.TGZ", writeTarGz},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(dir, test.name)
+			test.write(t, name)
+			fsys, err := OpenArchive(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The single export folder is stepped into.
+			n, diagnostics, err := Parser{AuthorDirs: true}.Parse(fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diagnostics) != 0 {
+				t.Errorf("diagnostics = %v", diagnostics)
+			}
+			if want := []string{"25-02-13.md", "25-02-14.md", "alice/25-02-14.md"}; !slices.Equal(n.Files, want) {
+				t.Errorf("Files = %q, want %q", n.Files, want)
+			}
+			if got := len(n.Entries()); got != 3 {
+				t.Errorf("got %d entries, want 3", got)
+			}
+		})
+	}
+
+	for _, name := range []string{filepath.Join(dir, "missing.zip"), dir} {
+		if _, err := OpenArchive(name); err == nil {
+			t.Errorf("OpenArchive(%q) gave no error", name)
+		}
+	}
+	unknown := filepath.Join(dir, "notes.rar")
+	if err := os.WriteFile(unknown, []byte("rar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenArchive(unknown); err == nil {
+		t.Error("OpenArchive of a .rar gave no error")
+	}
+}
+
+func TestTarFS(t *testing.T) {
+	fsys, err := TarFS(bytes.NewReader(tarBytes(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, sortedKeys(archiveNotes)...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(fsys, "export/latest.md"); err == nil {
+		t.Error("the symlink was read as a file")
+	}
+	info, err := fs.Stat(fsys, "export/25-02-14.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode() != 0o644 || !info.ModTime().Equal(time.Date(2025, time.February, 14, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("stat = %v %v, want the header's mode and time", info.Mode(), info.ModTime())
+	}
+}
+
+func TestNotesRoot(t *testing.T) {
+	note := &fstest.MapFile{Data: []byte("@trail +parser\n- a note\n")}
+	for _, test := range []struct {
+		name string
+		fsys fstest.MapFS
+		want string // a file the root should hold
+	}{
+		{"notes at the top", fstest.MapFS{"25-02-14.md": note, "alice/25-02-14.md": note}, "25-02-14.md"},
+		{"one folder", fstest.MapFS{"export/25-02-14.md": note}, "25-02-14.md"},
+		{"nested folders", fstest.MapFS{"a/b/c/25-02-14.md": note}, "25-02-14.md"},
+		{"a file beside the folder", fstest.MapFS{"export/25-02-14.md": note, "README": note}, "export/25-02-14.md"},
+		{"only author folders", fstest.MapFS{"alice/25-02-14.md": note, "bob/25-02-14.md": note}, "alice/25-02-14.md"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			root, err := notesRoot(test.fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := fs.Stat(root, test.want); err != nil {
+				t.Errorf("root has no %s: %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestGitTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// The @ in the repository's path isn't taken for the one before the
+	// revision.
+	repo := filepath.Join(t.TempDir(), "me@work", "notes")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=trail", "GIT_AUTHOR_EMAIL=trail@example.com",
+			"GIT_COMMITTER_NAME=trail", "GIT_COMMITTER_EMAIL=trail@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	for _, file := range sortedKeys(archiveNotes) {
+		name := filepath.Join(repo, "notes", filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(archiveNotes[file]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "notes")
+
+	for _, rev := range []string{"HEAD", "HEAD:notes", "HEAD:notes/export", "HEAD@{0}:notes"} {
+		fsys, err := OpenArchive(repo + "@" + rev)
+		if err != nil {
+			t.Fatalf("%s: %v", rev, err)
+		}
+		if _, err := fs.Stat(fsys, "alice/25-02-14.md"); err != nil {
+			t.Errorf("%s: %v", rev, err)
+		}
+	}
+
+	if _, err := OpenArchive(repo + "-old@HEAD"); err == nil {
+		t.Error("OpenArchive of a repository that doesn't exist gave no error")
+	}
+
+	// A revision that looks like an option is still read as a revision.
+	out := filepath.Join(t.TempDir(), "out.tar")
+	if _, err := GitTree(repo, "--output="+out); err == nil {
+		t.Error("GitTree with an option for a revision gave no error")
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("the revision was passed to git as an option")
+	}
+}
@@ -0,0 +1,227 @@
+package notes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheVersion changes whenever what the cache stores, or how files are
+// parsed, changes. A cache from any other version is thrown away.
+const cacheVersion = 6
+
+// Cache remembers what each file parsed to between runs, so that only new
+// and changed files are parsed again. A file is reused without being read
+// if its size and modification time are unchanged, or if it is read and
+// its content hash matches.
+//
+// A Cache is safe for concurrent use. A nil *Cache caches nothing.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	files   map[string]cachedFile
+	used    map[string]bool // keys looked up or stored since OpenCache
+	sources map[string]bool // Parser.Source of every Parse since OpenCache
+	dirty   bool
+
+	hits, misses int
+}
+
+// cachedFile is one file's entry in the cache.
+type cachedFile struct {
+	Size        int64
+	ModTime     time.Time
+	Hash        [sha256.Size]byte
+	Layout      string      // date layout the file was parsed with
+	Tasks       [][2]string // every @project +task heading, entries or not
+	Entries     []Entry
+	Diagnostics []Diagnostic
+}
+
+// cacheData is the cache as stored on disk.
+type cacheData struct {
+	Version int
+	Files   map[string]cachedFile
+}
+
+// OpenCache loads the cache stored at path. A missing file gives an empty
+// cache. So does a file that is corrupt or from another version of trail,
+// along with an error saying so; the cache is still usable and Save
+// replaces the file.
+func OpenCache(path string) (*Cache, error) {
+	c := &Cache{
+		path:    path,
+		files:   make(map[string]cachedFile),
+		used:    make(map[string]bool),
+		sources: make(map[string]bool),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	var stored cacheData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil {
+		c.dirty = true
+		return c, fmt.Errorf("%s: corrupt, parsing every file: %w", path, err)
+	}
+	if stored.Version != cacheVersion {
+		c.dirty = true
+		return c, fmt.Errorf("%s: from cache version %d, not %d, parsing every file", path, stored.Version, cacheVersion)
+	}
+	if stored.Files != nil {
+		c.files = stored.Files
+	}
+	return c, nil
+}
+
+// Stats reports how many files were reused from the cache and how many had
+// to be parsed since it was opened.
+func (c *Cache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Save writes the cache back to disk if anything changed. Files of a source
+// that was parsed since OpenCache but not seen in it are dropped, so deleted
+// notes don't linger; sources not parsed this run are kept as they were.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stored := cacheData{Version: cacheVersion, Files: make(map[string]cachedFile, len(c.files))}
+	for key, file := range c.files {
+		source, _, _ := strings.Cut(key, "\x00")
+		if c.used[key] || !c.sources[source] {
+			stored.Files[key] = file
+		}
+	}
+	if len(stored.Files) != len(c.files) {
+		c.dirty = true
+	}
+	if !c.dirty {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(stored); err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(c.path, buf.Bytes()); err != nil {
+		return err
+	}
+	c.files = stored.Files
+	c.dirty = false
+	return nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash never leaves a half-written file behind.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cacheKey is what a file is stored under: its source, its name within
+// it, and the default task, which changes what it parses to.
+func cacheKey(source, name, defaultTask string) string {
+	return source + "\x00" + name + "\x00" + defaultTask
+}
+
+// readSource records that source is being parsed, so Save drops its files
+// that weren't seen.
+func (c *Cache) readSource(source string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources[source] = true
+}
+
+// lookup returns the cached result for key if info still matches it.
+func (c *Cache) lookup(key, layout string, info fs.FileInfo) (cachedFile, bool) {
+	if c == nil {
+		return cachedFile{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.files[key]
+	if !ok || cached.Layout != layout || cached.Size != info.Size() || !cached.ModTime.Equal(info.ModTime()) {
+		return cachedFile{}, false
+	}
+	c.used[key] = true
+	c.hits++
+	return cached, true
+}
+
+// lookupHash returns the cached result for key if the file's content is
+// unchanged even though its modification time isn't, updating the time.
+func (c *Cache) lookupHash(key, layout string, info fs.FileInfo, hash [sha256.Size]byte) (cachedFile, bool) {
+	if c == nil {
+		return cachedFile{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.files[key]
+	if !ok || cached.Layout != layout || cached.Hash != hash {
+		return cachedFile{}, false
+	}
+	cached.Size, cached.ModTime = info.Size(), info.ModTime()
+	c.files[key] = cached
+	c.used[key] = true
+	c.dirty = true
+	c.hits++
+	return cached, true
+}
+
+func (c *Cache) store(key string, file cachedFile) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[key] = file
+	c.used[key] = true
+	c.dirty = true
+	c.misses++
+}
+
+// result rebuilds what parsing the file produced.
+func (cf cachedFile) result() fileResult {
+	n := New()
+	for _, task := range cf.Tasks {
+		n.addTask(task[0], task[1])
+	}
+	for _, entry := range cf.Entries {
+		n.Add(entry)
+	}
+	return fileResult{notes: n, diagnostics: cf.Diagnostics}
+}
@@ -0,0 +1,143 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var cacheTime = time.Date(2025, time.February, 14, 9, 0, 0, 0, time.UTC)
+
+func cacheNotes() fstest.MapFS {
+	return fstest.MapFS{
+		"25-02-13.md": {Data: []byte("@trail +parser\n- wrote the lexer\n"), ModTime: cacheTime},
+		"25-02-14.md": {Data: []byte("@trail +cache\n- keyed by mtime\n"), ModTime: cacheTime},
+	}
+}
+
+// parseCached opens the cache at path, parses each source with it and saves
+// it, returning the cache and what the last source parsed to.
+func parseCached(t *testing.T, path string, sources map[string]fstest.MapFS) (*Cache, *Notes) {
+	t.Helper()
+	cache, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n *Notes
+	for source, fsys := range sources {
+		if n, _, err = (Parser{Cache: cache, Source: source}).Parse(fsys); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return cache, n
+}
+
+func checkStats(t *testing.T, cache *Cache, wantHits, wantMisses int) {
+	t.Helper()
+	if hits, misses := cache.Stats(); hits != wantHits || misses != wantMisses {
+		t.Errorf("Stats() = %d hits, %d misses; want %d, %d", hits, misses, wantHits, wantMisses)
+	}
+}
+
+func TestCacheHit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	fsys := cacheNotes()
+	cache, _ := parseCached(t, path, map[string]fstest.MapFS{"notes": fsys})
+	checkStats(t, cache, 0, 2)
+
+	// Same size and time: the cached entries are used without reading the
+	// file, so a change that keeps both goes unnoticed.
+	fsys["25-02-14.md"].Data = []byte("@trail +cache\n- keyed by MTIME\n")
+	cache, n := parseCached(t, path, map[string]fstest.MapFS{"notes": fsys})
+	checkStats(t, cache, 2, 0)
+	if got := n.Task("trail", "cache")[0].Content; got != "- keyed by mtime" {
+		t.Errorf("cached content = %q, want the first parse's", got)
+	}
+}
+
+func TestCacheRehash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	fsys := cacheNotes()
+	parseCached(t, path, map[string]fstest.MapFS{"notes": fsys})
+
+	// A new time with the same content is a hit after hashing, and the new
+	// time is saved so the next run doesn't hash again.
+	fsys["25-02-13.md"].ModTime = cacheTime.Add(time.Hour)
+	cache, _ := parseCached(t, path, map[string]fstest.MapFS{"notes": fsys})
+	checkStats(t, cache, 2, 0)
+	if got := cache.files[cacheKey("notes", "25-02-13.md", "")].ModTime; !got.Equal(cacheTime.Add(time.Hour)) {
+		t.Errorf("cached time = %v, want the new one", got)
+	}
+
+	// A new time and new content is parsed again.
+	fsys["25-02-13.md"].ModTime = cacheTime.Add(2 * time.Hour)
+	fsys["25-02-13.md"].Data = []byte("@trail +parser\n- wrote the parser\n")
+	cache, _ = parseCached(t, path, map[string]fstest.MapFS{"notes": fsys})
+	checkStats(t, cache, 1, 1)
+}
+
+func TestCacheInvalid(t *testing.T) {
+	var stale bytes.Buffer
+	if err := gob.NewEncoder(&stale).Encode(cacheData{Version: cacheVersion - 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"corrupt", []byte("not a cache")},
+		{"old version", stale.Bytes()},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache.gob")
+			if err := os.WriteFile(path, test.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cache, err := OpenCache(path)
+			if err == nil {
+				t.Fatal("OpenCache gave no error")
+			}
+			if _, _, err := (Parser{Cache: cache}).Parse(cacheNotes()); err != nil {
+				t.Fatal(err)
+			}
+			checkStats(t, cache, 0, 2)
+			if err := cache.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			cache, _ = parseCached(t, path, map[string]fstest.MapFS{"": cacheNotes()})
+			checkStats(t, cache, 2, 0)
+		})
+	}
+}
+
+func TestCacheSavePrunesParsedSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.gob")
+	notes, other := cacheNotes(), cacheNotes()
+	parseCached(t, path, map[string]fstest.MapFS{"notes": notes, "other": other})
+
+	// A run on other alone forgets its deleted note but keeps notes's.
+	delete(other, "25-02-13.md")
+	cache, _ := parseCached(t, path, map[string]fstest.MapFS{"other": other})
+	checkStats(t, cache, 1, 0)
+	for key, want := range map[string]bool{
+		cacheKey("notes", "25-02-13.md", ""): true,
+		cacheKey("notes", "25-02-14.md", ""): true,
+		cacheKey("other", "25-02-13.md", ""): false,
+		cacheKey("other", "25-02-14.md", ""): true,
+	} {
+		if _, ok := cache.files[key]; ok != want {
+			t.Errorf("cached %q = %t, want %t", key, ok, want)
+		}
+	}
+
+	cache, _ = parseCached(t, path, map[string]fstest.MapFS{"notes": notes})
+	checkStats(t, cache, 2, 0)
+}
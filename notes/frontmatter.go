@@ -0,0 +1,99 @@
+package notes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter is the YAML block a note may open with, between "---"
+// lines, setting things for the whole file:
+//
+//	---
+//	date: 2025-02-14
+//	project: trail
+//	task: parser
+//	author: alice
+//	tags: [release, review]
+//	---
+type frontMatter struct {
+	Date    string   `yaml:"date"`    // YYYY-MM-DD, instead of the file name's
+	Project string   `yaml:"project"` // for entries and headings without one
+	Task    string   `yaml:"task"`    // for entries and headings without one
+	Author  string   `yaml:"author"`  // instead of the author directory
+	Tags    []string `yaml:"tags"`    // on every entry in the file
+
+	date time.Time // Date, parsed
+}
+
+// frontMatterKeys is every key a front matter block may set.
+var frontMatterKeys = []string{"date", "project", "task", "author", "tags"}
+
+// splitFrontMatter returns the lines of the front matter block at the top
+// of lines, and how many lines it takes up including its "---" fences. A
+// note without one gives 0.
+func splitFrontMatter(lines []string) (block []string, length int) {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return nil, 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if end := strings.TrimRight(lines[i], " \t"); end == "---" || end == "..." {
+			return lines[1:i], i + 1
+		}
+	}
+	return nil, 0
+}
+
+// parseFrontMatter reads a front matter block. Problems are returned as
+// messages rather than errors: a bad block is ignored and the rest of the
+// note still parses.
+func parseFrontMatter(block []string) (fm frontMatter, problems []string) {
+	text := strings.Join(block, "\n")
+	if err := yaml.Unmarshal([]byte(text), &fm); err != nil {
+		return frontMatter{}, []string{"front matter is not valid YAML, so it is ignored: " + err.Error()}
+	}
+
+	var keys map[string]any
+	if err := yaml.Unmarshal([]byte(text), &keys); err == nil {
+		var unknown []string
+		for key := range keys {
+			if !slices.Contains(frontMatterKeys, key) {
+				unknown = append(unknown, key)
+			}
+		}
+		slices.Sort(unknown)
+		for _, key := range unknown {
+			problems = append(problems, fmt.Sprintf("front matter key %q is not one of %s", key, strings.Join(frontMatterKeys, ", ")))
+		}
+	}
+
+	if fm.Date != "" {
+		date, err := time.Parse("2006-01-02", fm.Date)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("front matter date %q is not YYYY-MM-DD", fm.Date))
+		}
+		fm.date = date
+	}
+	for _, name := range []struct {
+		key   string
+		value *string
+	}{{"project", &fm.Project}, {"task", &fm.Task}, {"author", &fm.Author}} {
+		if *name.value != "" && !nameRegex.MatchString(*name.value) {
+			problems = append(problems, fmt.Sprintf("front matter %s %q may only contain letters, digits, _, . and -", name.key, *name.value))
+			*name.value = ""
+		}
+	}
+	var tags []string
+	for _, tag := range fm.Tags {
+		if !nameRegex.MatchString(tag) {
+			problems = append(problems, fmt.Sprintf("front matter tag %q may only contain letters, digits, _, . and -", tag))
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	fm.Tags = tags
+	return fm, problems
+}
@@ -0,0 +1,33 @@
+package notes
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	fm, problems := parseFrontMatter(strings.Split(`date: 2025-02-14
+project: trail
+task: "[red]x"
+author: "[red]carol"
+tags: [release, "[::b]loud", v1]`, "\n"))
+	if fm.Project != "trail" || !fm.date.Equal(day(time.February, 14)) {
+		t.Errorf("front matter = %+v, want the valid project and date kept", fm)
+	}
+	if fm.Task != "" || fm.Author != "" {
+		t.Errorf("task %q, author %q: want names with markup dropped", fm.Task, fm.Author)
+	}
+	if want := []string{"release", "v1"}; !slices.Equal(fm.Tags, want) {
+		t.Errorf("tags = %q, want %q", fm.Tags, want)
+	}
+	want := []string{
+		`front matter task "[red]x" may only contain letters, digits, _, . and -`,
+		`front matter author "[red]carol" may only contain letters, digits, _, . and -`,
+		`front matter tag "[::b]loud" may only contain letters, digits, _, . and -`,
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("problems =\n%q\nwant\n%q", problems, want)
+	}
+}
@@ -0,0 +1,115 @@
+package notes
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// memFS is a read-only in-memory file system holding the files of an
+// archive by their slash-separated paths. Directories aren't stored; they
+// exist wherever a file is below them.
+type memFS map[string]*memFile
+
+// memFile is a file's content and metadata.
+type memFile struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if file, ok := m[name]; ok {
+		info := memInfo{name: path.Base(name), size: int64(len(file.data)), mode: file.mode, modTime: file.modTime}
+		return &openMemFile{info: info, Reader: bytes.NewReader(file.data)}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	for fileName, file := range m {
+		rest, ok := strings.CutPrefix(fileName, prefix)
+		if !ok {
+			continue
+		}
+		child, _, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		if isDir {
+			entries = append(entries, memInfo{name: child, mode: fs.ModeDir | 0o555})
+		} else {
+			entries = append(entries, memInfo{name: child, size: int64(len(file.data)), mode: file.mode, modTime: file.modTime})
+		}
+	}
+	if len(entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return &openMemDir{info: memInfo{name: path.Base(name), mode: fs.ModeDir | 0o555}, entries: entries}, nil
+}
+
+// memInfo describes a file or directory of a memFS.
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memInfo) Name() string               { return i.name }
+func (i memInfo) Size() int64                { return i.size }
+func (i memInfo) Mode() fs.FileMode          { return i.mode }
+func (i memInfo) ModTime() time.Time         { return i.modTime }
+func (i memInfo) IsDir() bool                { return i.mode.IsDir() }
+func (i memInfo) Sys() any                   { return nil }
+func (i memInfo) Type() fs.FileMode          { return i.mode.Type() }
+func (i memInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// openMemFile is a memFS file being read.
+type openMemFile struct {
+	info memInfo
+	*bytes.Reader
+}
+
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openMemFile) Close() error               { return nil }
+
+// openMemDir is a memFS directory being listed.
+type openMemDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+}
+
+func (d *openMemDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openMemDir) Close() error               { return nil }
+
+func (d *openMemDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *openMemDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,287 @@
+// Package notes reads trail's dated markdown work notes into projects,
+// tasks and entries.
+//
+// A note file carries its date in its name (25-02-14.md by default), and
+// may sit in a directory named after its author. A heading line names a
+// @project and a +task, and the lines below it that start with "*", "-" or
+// whitespace are that task's entries for the day:
+//
+//	@trail +parser
+//	- moved the parser into its own package
+//
+// A heading with only a +task stays in the project above it, and one with
+// only a @project can fall back on Parser.DefaultTask.
+//
+// Parse reads every note at the top of an fs.FS, so notes can come from a
+// directory (os.DirFS), an archive or an embed.FS.
+package notes
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is one line recorded under a @project +task heading.
+type Entry struct {
+	Project string
+	Task    string
+	Date    time.Time // from the file name, in UTC
+	Content string    // the line as written, bullet included
+	File    string    // note the entry was read from
+	Line    int       // line number in File, from 1
+	Source  string    // where File is, as named by Parser.Source
+	Author  string    // who wrote it, if known; see Parser.AuthorDirs
+	Tags    []string  // from the note's front matter
+}
+
+// Compare orders entries chronologically, and entries from the same day by
+// where they were written: source, file name, then line. It is a total order, so
+// sorting with it gives the same result on every run.
+func Compare(a, b Entry) int {
+	if c := a.Date.Compare(b.Date); c != 0 {
+		return c
+	}
+	return compareSource(a, b)
+}
+
+// compareSource orders entries by where they were written, falling back on
+// the remaining fields for entries built by hand without a position.
+func compareSource(a, b Entry) int {
+	return cmp.Or(
+		strings.Compare(a.Source, b.Source),
+		strings.Compare(a.File, b.File),
+		cmp.Compare(a.Line, b.Line),
+		strings.Compare(a.Project, b.Project),
+		strings.Compare(a.Task, b.Task),
+		strings.Compare(a.Content, b.Content),
+	)
+}
+
+// Project is a @project and the entries of each of its +tasks.
+type Project struct {
+	Name  string
+	Tasks map[string][]Entry
+}
+
+// TaskNames returns the project's task names in order.
+func (p Project) TaskNames() []string {
+	names := make([]string, 0, len(p.Tasks))
+	for name := range p.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Notes is everything read from a set of note files.
+type Notes struct {
+	Projects map[string]Project
+	Files    []string // note files read
+}
+
+// New returns an empty Notes ready for Add.
+func New() *Notes {
+	return &Notes{Projects: make(map[string]Project)}
+}
+
+// Add records entry under its project and task, keeping the task's entries
+// in Compare order.
+func (n *Notes) Add(entry Entry) {
+	n.addTask(entry.Project, entry.Task)
+	tasks := n.Projects[entry.Project].Tasks
+	entries := tasks[entry.Task]
+	// Entries usually arrive in order, so look for the place from the end.
+	i := len(entries)
+	for i > 0 && Compare(entries[i-1], entry) > 0 {
+		i--
+	}
+	tasks[entry.Task] = slices.Insert(entries, i, entry)
+}
+
+// addTask makes sure a project and task exist, even with no entries yet.
+func (n *Notes) addTask(project, task string) {
+	p, ok := n.Projects[project]
+	if !ok {
+		p = Project{Name: project, Tasks: make(map[string][]Entry)}
+		n.Projects[project] = p
+	}
+	if _, ok := p.Tasks[task]; !ok {
+		p.Tasks[task] = nil
+	}
+}
+
+// Merge adds other's files, projects, tasks and entries to n, keeping each
+// task's entries in Compare order.
+func (n *Notes) Merge(other *Notes) {
+	n.appendNotes(other)
+	for _, project := range other.Projects {
+		tasks := n.Projects[project.Name].Tasks
+		for task := range project.Tasks {
+			slices.SortStableFunc(tasks[task], Compare)
+		}
+	}
+}
+
+// appendNotes adds other's files, projects, tasks and entries to the end of
+// n's without sorting, for callers that merge many and sort once with
+// sortTasks.
+func (n *Notes) appendNotes(other *Notes) {
+	n.Files = append(n.Files, other.Files...)
+	for _, project := range other.Projects {
+		for task, entries := range project.Tasks {
+			n.addTask(project.Name, task)
+			tasks := n.Projects[project.Name].Tasks
+			tasks[task] = append(tasks[task], entries...)
+		}
+	}
+}
+
+// sortTasks puts every task's entries in Compare order.
+func (n *Notes) sortTasks() {
+	for _, project := range n.Projects {
+		for _, entries := range project.Tasks {
+			slices.SortStableFunc(entries, Compare)
+		}
+	}
+}
+
+// Filter returns the notes with only the entries keep reports true for.
+// Tasks left without entries are dropped, and Files is copied as it is.
+func (n *Notes) Filter(keep func(Entry) bool) *Notes {
+	filtered := New()
+	filtered.Files = slices.Clone(n.Files)
+	for _, project := range n.Projects {
+		for _, entries := range project.Tasks {
+			for _, entry := range entries {
+				if keep(entry) {
+					filtered.Add(entry)
+				}
+			}
+		}
+	}
+	return filtered
+}
+
+// RenameProject gives a project a new name, merging it into the project
+// already called that if there is one.
+func (n *Notes) RenameProject(from, to string) {
+	project, ok := n.Projects[from]
+	if !ok || from == to {
+		return
+	}
+	delete(n.Projects, from)
+	for task, entries := range project.Tasks {
+		n.addTask(to, task)
+		for _, entry := range entries {
+			entry.Project = to
+			n.Add(entry)
+		}
+	}
+}
+
+// ProjectNames returns every project name in order.
+func (n *Notes) ProjectNames() []string {
+	names := make([]string, 0, len(n.Projects))
+	for name := range n.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Task returns the entries of one project's task, or nil if there is no
+// such task.
+func (n *Notes) Task(project, task string) []Entry {
+	return n.Projects[project].Tasks[task]
+}
+
+// Entries returns every entry in Compare order.
+func (n *Notes) Entries() []Entry {
+	return n.Between(time.Time{}, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+}
+
+// Between returns the entries dated from from to to inclusive, in Compare
+// order.
+func (n *Notes) Between(from, to time.Time) []Entry {
+	var entries []Entry
+	for _, project := range n.Projects {
+		for _, taskEntries := range project.Tasks {
+			for _, entry := range taskEntries {
+				if !entry.Date.Before(from) && !entry.Date.After(to) {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	slices.SortFunc(entries, Compare)
+	return entries
+}
+
+// On returns the entries of a single day, in the order they were written.
+func (n *Notes) On(date time.Time) []Entry {
+	return n.Between(date, date)
+}
+
+// Authors returns the name of everyone who wrote an entry, in order.
+// Entries without an author are left out.
+func (n *Notes) Authors() []string {
+	seen := make(map[string]bool)
+	var authors []string
+	for _, project := range n.Projects {
+		for _, entries := range project.Tasks {
+			for _, entry := range entries {
+				if entry.Author != "" && !seen[entry.Author] {
+					seen[entry.Author] = true
+					authors = append(authors, entry.Author)
+				}
+			}
+		}
+	}
+	sort.Strings(authors)
+	return authors
+}
+
+// Dates returns every date that has an entry, oldest first.
+func (n *Notes) Dates() []time.Time {
+	seen := make(map[time.Time]bool)
+	var dates []time.Time
+	for _, project := range n.Projects {
+		for _, entries := range project.Tasks {
+			for _, entry := range entries {
+				if !seen[entry.Date] {
+					seen[entry.Date] = true
+					dates = append(dates, entry.Date)
+				}
+			}
+		}
+	}
+	slices.SortFunc(dates, time.Time.Compare)
+	return dates
+}
+
+// NewestFirst returns a copy of entries ordered newest day first, and in
+// the order they were written within a day.
+func NewestFirst(entries []Entry) []Entry {
+	sorted := slices.Clone(entries)
+	slices.SortFunc(sorted, func(a, b Entry) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		return compareSource(a, b)
+	})
+	return sorted
+}
+
+// LastDate returns the date of the newest entry, or the zero time.
+func LastDate(entries []Entry) time.Time {
+	var last time.Time
+	for _, entry := range entries {
+		if entry.Date.After(last) {
+			last = entry.Date
+		}
+	}
+	return last
+}
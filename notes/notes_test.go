@@ -0,0 +1,110 @@
+package notes
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// sampleNotes is three days of entries across two projects.
+func sampleNotes() *Notes {
+	n := New()
+	n.Files = []string{"25-02-12.md", "25-02-13.md", "25-02-14.md"}
+	for _, entry := range []Entry{
+		{Project: "trail", Task: "parser", Date: day(time.February, 14), File: "25-02-14.md", Line: 2, Content: "- friday"},
+		{Project: "trail", Task: "parser", Date: day(time.February, 12), File: "25-02-12.md", Line: 2, Content: "- wednesday"},
+		{Project: "garden", Task: "beds", Date: day(time.February, 14), File: "25-02-14.md", Line: 5, Content: "- dug", Author: "alice"},
+		{Project: "trail", Task: "ui", Date: day(time.February, 13), File: "25-02-13.md", Line: 2, Content: "- thursday", Author: "bob"},
+		{Project: "trail", Task: "parser", Date: day(time.February, 14), File: "25-02-14.md", Line: 1, Content: "- friday, earlier"},
+	} {
+		n.Add(entry)
+	}
+	return n
+}
+
+// contents lists what each entry says, to compare orders at a glance.
+func contents(entries []Entry) []string {
+	var texts []string
+	for _, entry := range entries {
+		texts = append(texts, entry.Content)
+	}
+	return texts
+}
+
+func TestBetweenOnDates(t *testing.T) {
+	n := sampleNotes()
+	for _, test := range []struct {
+		name string
+		got  []Entry
+		want []string
+	}{
+		{"Entries", n.Entries(), []string{"- wednesday", "- thursday", "- friday, earlier", "- friday", "- dug"}},
+		{"Between", n.Between(day(time.February, 13), day(time.February, 14)), []string{"- thursday", "- friday, earlier", "- friday", "- dug"}},
+		{"Between, empty", n.Between(day(time.March, 1), day(time.March, 31)), nil},
+		{"On", n.On(day(time.February, 14)), []string{"- friday, earlier", "- friday", "- dug"}},
+		{"On, no entries", n.On(day(time.February, 15)), nil},
+		{"NewestFirst", NewestFirst(n.Entries()), []string{"- friday, earlier", "- friday", "- dug", "- thursday", "- wednesday"}},
+		{"Task", n.Task("trail", "parser"), []string{"- wednesday", "- friday, earlier", "- friday"}},
+	} {
+		if got := contents(test.got); !slices.Equal(got, test.want) {
+			t.Errorf("%s = %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	want := []time.Time{day(time.February, 12), day(time.February, 13), day(time.February, 14)}
+	if got := n.Dates(); !slices.Equal(got, want) {
+		t.Errorf("Dates() = %v, want %v", got, want)
+	}
+	if got := LastDate(n.Entries()); !got.Equal(day(time.February, 14)) {
+		t.Errorf("LastDate() = %v", got)
+	}
+	if got := n.Authors(); !slices.Equal(got, []string{"alice", "bob"}) {
+		t.Errorf("Authors() = %q", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	n := sampleNotes()
+	filtered := n.Filter(func(entry Entry) bool { return entry.Author == "" })
+	if got := contents(filtered.Entries()); !slices.Equal(got, []string{"- wednesday", "- friday, earlier", "- friday"}) {
+		t.Errorf("filtered entries = %q", got)
+	}
+	if got := filtered.ProjectNames(); !slices.Equal(got, []string{"trail"}) {
+		t.Errorf("filtered ProjectNames() = %q, want garden dropped", got)
+	}
+	if got := filtered.Projects["trail"].TaskNames(); !slices.Equal(got, []string{"parser"}) {
+		t.Errorf("filtered trail tasks = %q, want ui dropped", got)
+	}
+	if !slices.Equal(filtered.Files, n.Files) {
+		t.Errorf("filtered Files = %q, want %q", filtered.Files, n.Files)
+	}
+	if got := len(n.Entries()); got != 5 {
+		t.Errorf("Filter changed the original, which has %d entries", got)
+	}
+}
+
+func TestRenameProject(t *testing.T) {
+	n := sampleNotes()
+	n.RenameProject("garden", "home")
+	if got := n.ProjectNames(); !slices.Equal(got, []string{"home", "trail"}) {
+		t.Errorf("ProjectNames() = %q", got)
+	}
+	if got := n.Task("home", "beds"); len(got) != 1 || got[0].Project != "home" {
+		t.Errorf("home +beds = %+v", got)
+	}
+
+	// Renaming onto an existing project merges the two, in Compare order.
+	n.RenameProject("home", "trail")
+	if got := n.ProjectNames(); !slices.Equal(got, []string{"trail"}) {
+		t.Errorf("ProjectNames() after merging = %q", got)
+	}
+	if got := n.Projects["trail"].TaskNames(); !slices.Equal(got, []string{"beds", "parser", "ui"}) {
+		t.Errorf("trail tasks = %q", got)
+	}
+
+	n.RenameProject("missing", "trail")
+	n.RenameProject("trail", "trail")
+	if got := len(n.Entries()); got != 5 {
+		t.Errorf("got %d entries after no-op renames, want 5", got)
+	}
+}
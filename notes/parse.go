@@ -0,0 +1,380 @@
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDateLayout is the date in note file names unless a Parser says
+// otherwise, e.g. 25-02-14.md.
+const DefaultDateLayout = "06-01-02"
+
+// Diagnostic is a problem found while parsing a note, such as an entry
+// that sits under no @project +task heading. Parsing carries on past it.
+type Diagnostic struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// Parser reads note files. The zero value is ready to use.
+type Parser struct {
+	// DateLayout is the Go time layout of the date in note file names,
+	// DefaultDateLayout if empty. It must be numeric so it can be found
+	// anywhere in the name.
+	DateLayout string
+
+	// AuthorDirs also reads the notes one directory down, as written by the
+	// person the directory is named after: alice/25-02-14.md is Alice's.
+	// Entries in notes at the top have no author.
+	AuthorDirs bool
+
+	// DefaultTask files the entries under a heading with a @project but no
+	// +task, such as "general". If empty, such headings are reported and
+	// their entries skipped. A task in the note's front matter comes first.
+	DefaultTask string
+
+	// Workers is how many files are parsed at once, GOMAXPROCS if zero and
+	// never more than that.
+	Workers int
+
+	// Debug, if set, logs every line as it is parsed.
+	Debug *log.Logger
+
+	// Cache, if set, holds files parsed on earlier runs. Files are keyed by
+	// Source, their name and DefaultTask, and saving it only forgets files
+	// of the Sources parsed since it was opened.
+	Cache *Cache
+
+	// Source names where the notes come from, such as their directory. It
+	// is recorded on every Entry, so notes read from several places can be
+	// told apart after a Merge. Give each fs.FS a distinct Source.
+	Source string
+}
+
+var (
+	projectRegex = regexp.MustCompile(`(?:^|\s)@([a-zA-Z0-9_.-]+)`)
+	taskRegex    = regexp.MustCompile(`(?:^|\s)\+([a-zA-Z0-9_.-]+)`)
+	entryRegex   = regexp.MustCompile(`^(?:\*|-|\s)`)
+	nameRegex    = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// ValidName reports whether name can be a @project or +task: letters,
+// digits, _, . and - only.
+func ValidName(name string) bool {
+	return nameRegex.MatchString(name)
+}
+
+// Parse reads every .md file at the top of fsys with the default Parser.
+func Parse(fsys fs.FS) (*Notes, []Diagnostic, error) {
+	return Parser{}.Parse(fsys)
+}
+
+// fileResult is what parsing one file produced.
+type fileResult struct {
+	notes       *Notes
+	diagnostics []Diagnostic
+	err         error
+}
+
+// parallelMinFiles is the fewest notes worth starting workers for; smaller
+// trees parse faster on one goroutine.
+const parallelMinFiles = 64
+
+// Parse reads every .md file at the top of fsys, and in its directories
+// with AuthorDirs, returning what it found and any diagnostics. It only
+// fails if fsys or a file can't be read.
+//
+// Files are parsed in parallel and merged in name order, so the result is
+// the same from run to run.
+func (p Parser) Parse(fsys fs.FS) (*Notes, []Diagnostic, error) {
+	names, err := p.noteNames(fsys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	layout := p.dateLayout()
+	dateRegex := dateRegex(layout)
+	results := make([]fileResult, len(names))
+	workers := runtime.GOMAXPROCS(0)
+	if p.Workers > 0 {
+		workers = min(p.Workers, workers)
+	}
+	if workers == 1 || len(names) < parallelMinFiles {
+		for i, name := range names {
+			results[i] = p.parseFile(fsys, name, layout, dateRegex)
+		}
+	} else {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					results[i] = p.parseFile(fsys, names[i], layout, dateRegex)
+				}
+			}()
+		}
+		for i := range names {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	n := New()
+	var diagnostics []Diagnostic
+	for i, result := range results {
+		if result.err != nil {
+			return nil, nil, result.err
+		}
+		n.Files = append(n.Files, names[i])
+		n.appendNotes(result.notes)
+		diagnostics = append(diagnostics, result.diagnostics...)
+	}
+	n.sortTasks()
+	p.Cache.readSource(p.Source)
+	return n, diagnostics, nil
+}
+
+// noteNames lists the notes to read, in name order: the .md files at the
+// top of fsys, and with AuthorDirs those in each directory not starting
+// with a dot.
+func (p Parser) noteNames(fsys fs.FS) ([]string, error) {
+	dirEntries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		switch {
+		case !dirEntry.IsDir():
+			if path.Ext(name) == ".md" {
+				names = append(names, name)
+			}
+		case p.AuthorDirs && !strings.HasPrefix(name, "."):
+			authorEntries, err := fs.ReadDir(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			for _, authorEntry := range authorEntries {
+				if !authorEntry.IsDir() && path.Ext(authorEntry.Name()) == ".md" {
+					names = append(names, path.Join(name, authorEntry.Name()))
+				}
+			}
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (p Parser) dateLayout() string {
+	if p.DateLayout == "" {
+		return DefaultDateLayout
+	}
+	return p.DateLayout
+}
+
+// dateRegex turns a numeric layout such as 06-01-02 into a pattern that
+// finds it in a file name.
+func dateRegex(layout string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(")
+	for _, r := range layout {
+		if r >= '0' && r <= '9' {
+			sb.WriteString(`\d`)
+		} else {
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString(")")
+	return regexp.MustCompile(sb.String())
+}
+
+func (p Parser) debugf(format string, args ...any) {
+	if p.Debug != nil {
+		p.Debug.Printf(format, args...)
+	}
+}
+
+// parseFile reads the entries of one note, from the cache if it is there.
+func (p Parser) parseFile(fsys fs.FS, name, layout string, dateRegex *regexp.Regexp) fileResult {
+	if p.Cache == nil {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fileResult{err: err}
+		}
+		return p.parseContent(name, content, layout, dateRegex)
+	}
+
+	key := cacheKey(p.Source, name, p.DefaultTask)
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return fileResult{err: err}
+	}
+	if cached, ok := p.Cache.lookup(key, layout, info); ok {
+		return cached.result()
+	}
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fileResult{err: err}
+	}
+	hash := sha256.Sum256(content)
+	if cached, ok := p.Cache.lookupHash(key, layout, info, hash); ok {
+		return cached.result()
+	}
+
+	result := p.parseContent(name, content, layout, dateRegex)
+	if result.err == nil {
+		cached := cachedFile{
+			Size:        info.Size(),
+			ModTime:     info.ModTime(),
+			Hash:        hash,
+			Layout:      layout,
+			Entries:     result.notes.Entries(),
+			Diagnostics: result.diagnostics,
+		}
+		for _, projectName := range result.notes.ProjectNames() {
+			for _, taskName := range result.notes.Projects[projectName].TaskNames() {
+				cached.Tasks = append(cached.Tasks, [2]string{projectName, taskName})
+			}
+		}
+		p.Cache.store(key, cached)
+	}
+	return result
+}
+
+// parseContent reads the entries of one note from its content.
+func (p Parser) parseContent(name string, content []byte, layout string, dateRegex *regexp.Regexp) fileResult {
+	n := New()
+	var diagnostics []Diagnostic
+	report := func(line int, message string) {
+		diagnostics = append(diagnostics, Diagnostic{File: name, Line: line, Message: message})
+	}
+
+	var date time.Time
+	if match := dateRegex.FindStringSubmatch(path.Base(name)); match != nil {
+		date, _ = time.Parse(layout, match[1])
+	}
+	undated := false
+	author := path.Dir(name)
+	if author == "." {
+		author = ""
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fileResult{err: err}
+	}
+
+	// Front matter overrides the date and author, and gives entries and
+	// headings a project and task to fall back on.
+	block, skip := splitFrontMatter(lines)
+	var fm frontMatter
+	if skip > 0 {
+		var problems []string
+		fm, problems = parseFrontMatter(block)
+		for _, problem := range problems {
+			report(1, problem)
+		}
+		p.debugf("%s:1-%d: front matter %+v", name, skip, fm)
+	}
+	if !fm.date.IsZero() {
+		date = fm.date
+	}
+	if fm.Author != "" {
+		author = fm.Author
+	}
+
+	currentProject, currentTask := fm.Project, fm.Task
+	skipping := false // under a heading that was reported, so its entries are too
+	for i := skip; i < len(lines); i++ {
+		text := lines[i]
+		lineNumber := i + 1
+		// if we are in an entry or new heading
+		if entryRegex.MatchString(text) {
+			if currentProject == "" || currentTask == "" {
+				if !skipping && strings.TrimSpace(text) != "" {
+					report(lineNumber, "entry is not under a @project +task heading")
+				}
+				continue
+			}
+			if date.IsZero() {
+				if !undated {
+					report(lineNumber, "file name has no "+layout+" date, nor has front matter, so its entries are skipped")
+					undated = true
+				}
+				continue
+			}
+			p.debugf("%s:%d: entry for @%s +%s", name, lineNumber, currentProject, currentTask)
+
+			// TODO maybe some sanitization here to take out bullets/dashes etc.
+			// TODO handle TODOS
+			n.Add(Entry{
+				Project: currentProject,
+				Task:    currentTask,
+				Date:    date,
+				Content: text,
+				File:    name,
+				Line:    lineNumber,
+				Source:  p.Source,
+				Author:  author,
+				Tags:    fm.Tags,
+			})
+			continue
+		}
+
+		projectMatch := projectRegex.FindStringSubmatch(text)
+		taskMatch := taskRegex.FindStringSubmatch(text)
+		if projectMatch == nil && taskMatch == nil {
+			p.debugf("%s:%d: no @project or +task", name, lineNumber)
+			continue
+		}
+		// A heading with only a +task carries on the project above it, and
+		// one with only a @project falls back on the default task.
+		project, task := currentProject, cmp.Or(fm.Task, p.DefaultTask)
+		if projectMatch != nil {
+			project = projectMatch[1]
+		}
+		if taskMatch != nil {
+			task = taskMatch[1]
+		}
+		switch {
+		case project == "":
+			report(lineNumber, "heading has +"+task+" but no @project above it, so its entries are skipped")
+			currentTask, skipping = "", true
+			continue
+		case task == "":
+			report(lineNumber, "heading has @"+project+" but no +task, so its entries are skipped")
+			currentProject, currentTask, skipping = project, "", true
+			continue
+		}
+		currentProject, currentTask, skipping = project, task, false
+		p.debugf("%s:%d: heading @%s +%s", name, lineNumber, currentProject, currentTask)
+		n.addTask(currentProject, currentTask)
+	}
+
+	return fileResult{notes: n, diagnostics: diagnostics}
+}
@@ -0,0 +1,229 @@
+package notes
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// writeCorpus fills dir with a note per day for the given number of days,
+// each with a few projects, tasks and entries, like years of daily notes.
+func writeCorpus(tb testing.TB, dir string, days int) {
+	tb.Helper()
+	start := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := range days {
+		date := start.AddDate(0, 0, i)
+		var sb strings.Builder
+		for j := range 4 {
+			fmt.Fprintf(&sb, "worked on @project%d +task%d\n", (i+j)%12, (i*j)%30)
+			for k := range 6 {
+				fmt.Fprintf(&sb, "- entry %d about something that took a while to get right\n", k)
+			}
+			sb.WriteString("  continued on an indented line\n\n")
+		}
+		name := filepath.Join(dir, date.Format(DefaultDateLayout)+".md")
+		if err := os.WriteFile(name, []byte(sb.String()), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// parseNotes parses fsys with parser, failing the test on an error.
+func parseNotes(t *testing.T, parser Parser, fsys fs.FS) (*Notes, []Diagnostic) {
+	t.Helper()
+	n, diagnostics, err := parser.Parse(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n, diagnostics
+}
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	fsys := fstest.MapFS{
+		"25-02-13.md": {Data: []byte("@trail +parser\n- wrote the lexer\n  and its tests\n\n+review\n* read the diff\n")},
+		"25-02-14.md": {Data: []byte("notes for friday\n\n@garden +beds\n- dug the beds\n")},
+		"readme.txt":  {Data: []byte("@trail +ignored\n- not a note\n")},
+		"alice":       {Mode: fs.ModeDir},
+	}
+	n, diagnostics := parseNotes(t, Parser{Source: "notes"}, fsys)
+	if len(diagnostics) != 0 {
+		t.Errorf("diagnostics = %v, want none", diagnostics)
+	}
+	if want := []string{"25-02-13.md", "25-02-14.md"}; !slices.Equal(n.Files, want) {
+		t.Errorf("Files = %q, want %q", n.Files, want)
+	}
+
+	want := []Entry{
+		{Project: "trail", Task: "parser", Date: day(time.February, 13), Content: "- wrote the lexer", File: "25-02-13.md", Line: 2, Source: "notes"},
+		{Project: "trail", Task: "parser", Date: day(time.February, 13), Content: "  and its tests", File: "25-02-13.md", Line: 3, Source: "notes"},
+		{Project: "trail", Task: "review", Date: day(time.February, 13), Content: "* read the diff", File: "25-02-13.md", Line: 6, Source: "notes"},
+		{Project: "garden", Task: "beds", Date: day(time.February, 14), Content: "- dug the beds", File: "25-02-14.md", Line: 4, Source: "notes"},
+	}
+	if got := n.Entries(); !slices.EqualFunc(got, want, entryEqual) {
+		t.Errorf("Entries() =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := n.ProjectNames(); !slices.Equal(got, []string{"garden", "trail"}) {
+		t.Errorf("ProjectNames() = %q", got)
+	}
+	if got := n.Projects["trail"].TaskNames(); !slices.Equal(got, []string{"parser", "review"}) {
+		t.Errorf("trail TaskNames() = %q", got)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	fsys := fstest.MapFS{
+		"25-02-14.md": {Data: []byte("- before any heading\n+review\n- under a task alone\n@trail\n- under a project alone\n@trail +parser\n- fine\n")},
+		"undated.md":  {Data: []byte("@trail +parser\n- no date\n- still no date\n")},
+	}
+	n, diagnostics := parseNotes(t, Parser{}, fsys)
+	want := []Diagnostic{
+		{File: "25-02-14.md", Line: 1, Message: "entry is not under a @project +task heading"},
+		{File: "25-02-14.md", Line: 2, Message: "heading has +review but no @project above it, so its entries are skipped"},
+		{File: "25-02-14.md", Line: 4, Message: "heading has @trail but no +task, so its entries are skipped"},
+		{File: "undated.md", Line: 2, Message: "file name has no 06-01-02 date, nor has front matter, so its entries are skipped"},
+	}
+	if !slices.Equal(diagnostics, want) {
+		t.Errorf("diagnostics =\n%v\nwant\n%v", diagnostics, want)
+	}
+	if got := diagnostics[0].String(); got != "25-02-14.md:1: entry is not under a @project +task heading" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := n.Entries(); len(got) != 1 || got[0].Content != "- fine" {
+		t.Errorf("Entries() = %+v, want only the one under @trail +parser", got)
+	}
+
+	// With a default task the project-only heading is filed under it.
+	n, diagnostics = parseNotes(t, Parser{DefaultTask: "general"}, fsys)
+	if len(diagnostics) != 3 {
+		t.Errorf("diagnostics = %v, want all but the project-only heading", diagnostics)
+	}
+	if got := n.Task("trail", "general"); len(got) != 1 || got[0].Line != 5 {
+		t.Errorf("trail +general = %+v, want line 5", got)
+	}
+}
+
+func TestParseAuthorDirs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"25-02-14.md":       {Data: []byte("@trail +parser\n- nobody's\n")},
+		"alice/25-02-14.md": {Data: []byte("@trail +parser\n- alice's\n")},
+		".git/25-02-14.md":  {Data: []byte("@trail +parser\n- hidden\n")},
+	}
+	n, _ := parseNotes(t, Parser{}, fsys)
+	if got := len(n.Entries()); got != 1 {
+		t.Errorf("without AuthorDirs got %d entries, want 1", got)
+	}
+	n, _ = parseNotes(t, Parser{AuthorDirs: true}, fsys)
+	if want := []string{"25-02-14.md", "alice/25-02-14.md"}; !slices.Equal(n.Files, want) {
+		t.Errorf("Files = %q, want %q", n.Files, want)
+	}
+	if got := n.Authors(); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("Authors() = %q", got)
+	}
+}
+
+// entryEqual compares entries field by field, as Tags keeps them from ==.
+func entryEqual(a, b Entry) bool {
+	return Compare(a, b) == 0 && a.Author == b.Author && slices.Equal(a.Tags, b.Tags)
+}
+
+func benchmarkParse(b *testing.B, workers int) {
+	dir := b.TempDir()
+	writeCorpus(b, dir, 4000)
+	fsys := os.DirFS(dir)
+	parser := Parser{Workers: workers}
+	b.ResetTimer()
+	for range b.N {
+		if _, _, err := parser.Parse(fsys); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B)           { benchmarkParse(b, 0) }
+func BenchmarkParseSequential(b *testing.B) { benchmarkParse(b, 1) }
+
+func TestParseDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpus(t, dir, 90)
+	// Front matter dates out of name order, so merging in name order alone
+	// would not give Compare order.
+	for i, date := range []string{"2015-03-02", "2015-01-01", "2015-02-10"} {
+		note := fmt.Sprintf("---\ndate: %s\n---\n@project1 +task%d\n- moved %d\n", date, i, i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("moved-%d.md", i)), []byte(note), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fsys := os.DirFS(dir)
+
+	render := func(n *Notes) string {
+		var sb strings.Builder
+		fmt.Fprintln(&sb, n.Files)
+		for _, name := range n.ProjectNames() {
+			for _, task := range n.Projects[name].TaskNames() {
+				for _, entry := range n.Task(name, task) {
+					fmt.Fprintf(&sb, "%+v\n", entry)
+				}
+			}
+		}
+		for _, entry := range NewestFirst(n.Entries()) {
+			fmt.Fprintf(&sb, "%+v\n", entry)
+		}
+		return sb.String()
+	}
+
+	// Workers are capped at GOMAXPROCS, so raise it for the pool to run
+	// even on one CPU.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	var want string
+	for _, workers := range []int{1, 2, 3, 8, 0, 1} {
+		t.Run(fmt.Sprint("workers=", workers), func(t *testing.T) {
+			n, _, err := Parser{Workers: workers}.Parse(fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range n.ProjectNames() {
+				for _, task := range n.Projects[name].TaskNames() {
+					entries := n.Task(name, task)
+					if !slices.IsSortedFunc(entries, Compare) {
+						t.Errorf("@%s +%s entries are not in Compare order", name, task)
+					}
+				}
+			}
+			newest := NewestFirst(n.Entries())
+			if !slices.IsSortedFunc(newest, func(a, b Entry) int {
+				return cmp.Or(b.Date.Compare(a.Date), compareSource(a, b))
+			}) {
+				t.Error("NewestFirst is not newest first, then in source order")
+			}
+			got := render(n)
+			if want == "" {
+				want = got
+			} else if got != want {
+				t.Errorf("output differs from the first parse:\n%s", firstDifference(want, got))
+			}
+		})
+	}
+}
+
+// firstDifference shows the first line where got differs from want.
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := range min(len(wantLines), len(gotLines)) {
+		if wantLines[i] != gotLines[i] {
+			return fmt.Sprintf("line %d\nwant %s\n got %s", i+1, wantLines[i], gotLines[i])
+		}
+	}
+	return fmt.Sprintf("want %d lines, got %d", len(wantLines), len(gotLines))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"thesecondreal0/trail/notes"
+)
+
+// --- Pins ---
+
+// pinSet is the @project +task pairs the user has pinned, kept in pins.txt
+// in the state directory as one "project/task" per line, in pin order.
+type pinSet struct {
+	path string
+	keys []string
+}
+
+// pins is shared by every screen that lists tasks. main loads it from the
+// state directory; until then it is empty and never saved.
+var pins = &pinSet{}
+
+func pinKey(project, task string) string {
+	return project + "/" + task
+}
+
+// loadPins reads the pin file at path. A missing file just means nothing is
+// pinned yet.
+func loadPins(path string) (*pinSet, error) {
+	ps := &pinSet{path: path}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return ps, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if strings.Contains(key, "/") && !slices.Contains(ps.keys, key) {
+			ps.keys = append(ps.keys, key)
+		}
+	}
+	return ps, scanner.Err()
+}
+
+func (ps *pinSet) has(project, task string) bool {
+	return slices.Contains(ps.keys, pinKey(project, task))
+}
+
+// hasProject reports whether any of the project's tasks is pinned.
+func (ps *pinSet) hasProject(project string) bool {
+	return slices.ContainsFunc(ps.keys, func(key string) bool {
+		return strings.HasPrefix(key, project+"/")
+	})
+}
+
+// toggle pins or unpins a task and saves the file, reporting whether the
+// task is now pinned.
+func (ps *pinSet) toggle(project, task string) (bool, error) {
+	key := pinKey(project, task)
+	pinned := !slices.Contains(ps.keys, key)
+	if pinned {
+		ps.keys = append(ps.keys, key)
+	} else {
+		ps.keys = slices.DeleteFunc(ps.keys, func(k string) bool { return k == key })
+	}
+	return pinned, ps.save()
+}
+
+// save writes the pins back to pins.txt.
+func (ps *pinSet) save() error {
+	if ps.path == "" {
+		return nil
+	}
+	var sb strings.Builder
+	for _, key := range ps.keys {
+		sb.WriteString(key + "\n")
+	}
+	return notes.WriteFileAtomic(ps.path, []byte(sb.String()))
+}
+
+// comparePinned orders pinned "project/task" keys before unpinned ones. Use
+// it with a stable sort to give a list its pinned section.
+func comparePinned(a, b string) int {
+	return pinnedFirst(slices.Contains(pins.keys, a), slices.Contains(pins.keys, b))
+}
+
+// comparePinnedProjects orders projects with a pinned task before the rest,
+// for the projects list's pinned section.
+func comparePinnedProjects(a, b string) int {
+	return pinnedFirst(pins.hasProject(a), pins.hasProject(b))
+}
+
+func pinnedFirst(a, b bool) int {
+	switch {
+	case a && !b:
+		return -1
+	case b && !a:
+		return 1
+	}
+	return 0
+}
+
+// pinMarker prefixes pinned items in lists.
+func pinMarker(pinned bool) string {
+	if pinned {
+		return colorTag(theme.Accent) + "★[-] "
+	}
+	return ""
+}
+
+// --- PinnedScreen ---
+
+// pinnedEntries is how many of a pinned task's latest entries the landing
+// view shows.
+const pinnedEntries = 5
+
+// PinnedScreen is the landing view: the latest entries of every pinned
+// task, in the order they were pinned.
+type PinnedScreen struct {
+	Root    *tview.Grid
+	content *tview.TextView
+	help    *tview.TextView
+	data    *TrailData
+	app     *tview.Application
+}
+
+func newPinnedScreen(data *TrailData, app *tview.Application) *PinnedScreen {
+	ps := &PinnedScreen{data: data, app: app}
+
+	ps.content = tview.NewTextView().SetScrollable(true).SetDynamicColors(true)
+	ps.help = defaultText("p on a task in projects or tasks pins it · y copies these entries").
+		SetTextColor(tview.Styles.TertiaryTextColor)
+
+	ps.Root = tview.NewGrid().SetRows(0, 1).SetColumns(0).SetBorders(true)
+	ps.Root.AddItem(ps.content, 0, 0, 1, 1, 0, 0, true)
+	ps.Root.AddItem(ps.help, 1, 0, 1, 1, 0, 0, false)
+
+	ps.refresh()
+	return ps
+}
+
+// latest returns up to pinnedEntries of a task's newest entries, and how
+// many it has in all.
+func (ps *PinnedScreen) latest(project, task string) (entries []notes.Entry, total int) {
+	all := ps.data.Projects[project].Tasks[task]
+	sorted := notes.NewestFirst(all)
+	return sorted[:min(len(sorted), pinnedEntries)], len(all)
+}
+
+// refresh redraws the view after the pins or the notes change.
+func (ps *PinnedScreen) refresh() {
+	if len(pins.keys) == 0 {
+		ps.content.SetText("Nothing pinned yet.")
+		return
+	}
+	var sb strings.Builder
+	for i, key := range pins.keys {
+		project, task, _ := strings.Cut(key, "/")
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "%s@%s[-] %s+%s[-]", colorTag(theme.Project), project, colorTag(theme.Task), task)
+		entries, total := ps.latest(project, task)
+		if total == 0 {
+			fmt.Fprintf(&sb, "  %s(no entries)[-]", colorTag(theme.Dim))
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s%d %s, last %s[-]\n", colorTag(theme.Dim), total, plural(total, "entry", "entries"),
+			entries[0].Date.Format(longDate))
+		for j, line := range strings.Split(renderEntries(entries), "\n") {
+			if j > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("  " + tview.Escape(line))
+		}
+	}
+	ps.content.SetText(sb.String())
+	ps.content.ScrollToBeginning()
+}
+
+func (ps *PinnedScreen) restyle() {
+	restyle(ps.Root, ps.content, ps.help)
+	ps.help.SetTextColor(tview.Styles.TertiaryTextColor)
+}
+
+// yankText is every pinned task's latest entries.
+func (ps *PinnedScreen) yankText() (text, what string) {
+	parts := make([]string, 0, len(pins.keys))
+	for _, key := range pins.keys {
+		project, task, _ := strings.Cut(key, "/")
+		if entries, _ := ps.latest(project, task); len(entries) > 0 {
+			parts = append(parts, markdownTask(project, task, entries))
+		}
+	}
+	return strings.Join(parts, "\n"), "pinned entries"
+}
@@ -0,0 +1,603 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+
+	"thesecondreal0/trail/notes"
+)
+
+// --- RecentScreen ---
+
+type RecentScreen struct {
+	Root    *tview.Grid
+	days    *tview.InputField
+	content *recentView
+	data    *TrailData
+	app     *tview.Application
+}
+
+// newRecentScreen builds the recent boxes screen. onOpen is called with a
+// project (and task, if any) to show its full history elsewhere.
+func newRecentScreen(data *TrailData, app *tview.Application, days int, onOpen func(project, task string)) *RecentScreen {
+	rs := &RecentScreen{data: data, app: app}
+
+	rs.content = newRecentView(onOpen)
+	rs.content.setTree(buildRecentTree(days, data))
+
+	rs.days = tview.NewInputField().
+		SetLabel("Last N days: ").
+		SetText(strconv.Itoa(days)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetChangedFunc(func(text string) {
+			n, err := strconv.Atoi(text)
+			if err != nil || n <= 0 {
+				rs.content.setTree(nil)
+				return
+			}
+			rs.content.setTree(buildRecentTree(n, data))
+		})
+	rs.days.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			app.SetFocus(rs.content)
+		}
+	})
+
+	rs.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
+	rs.Root.AddItem(rs.days, 0, 0, 1, 1, 0, 0, false)
+	rs.Root.AddItem(rs.content, 1, 0, 1, 1, 0, 0, true)
+
+	return rs
+}
+
+// refresh rebuilds the boxes from the notes. The cursor and collapsed boxes
+// are kept by id, so they stay put where the boxes still exist.
+func (rs *RecentScreen) refresh() {
+	days, _ := strconv.Atoi(rs.days.GetText())
+	rs.content.setTree(buildRecentTree(days, rs.data))
+}
+
+func (rs *RecentScreen) restyle() {
+	restyle(rs.Root, rs.days, rs.content)
+}
+
+func (rs *RecentScreen) handleEsc() {
+	if rs.app.GetFocus() == rs.days {
+		rs.app.SetFocus(rs.content)
+	}
+}
+
+func (rs *RecentScreen) focusFilter() {
+	rs.app.SetFocus(rs.days)
+}
+
+// yankText is the box under the cursor: a project, a task or one day of a
+// task, limited to the entries in the window.
+func (rs *RecentScreen) yankText() (text, what string) {
+	node := rs.content.selectedNode()
+	if node == nil {
+		return "", ""
+	}
+	what = "@" + node.project
+	if node.task != "" {
+		what += " +" + node.task
+	}
+	if node.isDate() {
+		what += " " + node.date.Format(longDate)
+	}
+	return markdownRecentNode(node), what
+}
+
+// --- Recent boxes ---
+
+// recentNode is a project, task or date box in the recent screen.
+type recentNode struct {
+	id       string // unique across the tree, e.g. "t:trail:parser"
+	project  string
+	task     string        // empty for projects
+	date     time.Time     // zero for projects and tasks
+	entries  []notes.Entry // dates only
+	children []*recentNode
+	parent   *recentNode
+}
+
+func (n *recentNode) isProject() bool { return n.task == "" }
+func (n *recentNode) isDate() bool    { return !n.date.IsZero() }
+
+// contains reports whether other is n or one of its descendants.
+func (n *recentNode) contains(other *recentNode) bool {
+	for ; other != nil; other = other.parent {
+		if other == n {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRecentTree collects the entries of the last days days into projects,
+// tasks and dates: projects and tasks by name, dates newest first.
+func buildRecentTree(days int, data *TrailData) []*recentNode {
+	if days <= 0 {
+		return nil
+	}
+	today := currentDay()
+	cutoff := today.AddDate(0, 0, -(days - 1))
+
+	var tree []*recentNode
+	for _, projectName := range data.ProjectNames() {
+		project := data.Projects[projectName]
+		projectNode := &recentNode{id: "p:" + projectName, project: projectName}
+
+		for _, taskName := range project.TaskNames() {
+			taskNode := &recentNode{id: "t:" + projectName + ":" + taskName, project: projectName, task: taskName, parent: projectNode}
+			var dateNode *recentNode
+			for _, entry := range notes.NewestFirst(project.Tasks[taskName]) {
+				if entry.Date.Before(cutoff) || entry.Date.After(today) {
+					continue
+				}
+				if dateNode == nil || !dateNode.date.Equal(entry.Date) {
+					dateNode = &recentNode{
+						id:      taskNode.id + ":" + entry.Date.Format("2006-01-02"),
+						project: projectName,
+						task:    taskName,
+						date:    entry.Date,
+						parent:  taskNode,
+					}
+					taskNode.children = append(taskNode.children, dateNode)
+				}
+				dateNode.entries = append(dateNode.entries, entry)
+			}
+			if len(taskNode.children) > 0 {
+				projectNode.children = append(projectNode.children, taskNode)
+			}
+		}
+
+		if len(projectNode.children) > 0 {
+			tree = append(tree, projectNode)
+		}
+	}
+	return tree
+}
+
+// recentLine is one line of the rendered boxes. node is the innermost box
+// the line belongs to; header marks the line that opens it, where label
+// spans the columns of its name.
+type recentLine struct {
+	text   string
+	node   *recentNode
+	header bool
+	label  [2]int
+}
+
+// layoutRecentBoxes renders the tree as nested boxes sized to width (the
+// content area column count). Boxes whose id is in collapsed are drawn as a
+// single header with a summary of what they hide. Widths are measured in
+// terminal cells, so wide and combining characters keep the right borders
+// on the last column; long entries wrap and long names are truncated.
+func layoutRecentBoxes(tree []*recentNode, width int, collapsed map[string]bool) []recentLine {
+	if width < 12 {
+		return nil
+	}
+	boxTag := colorTag(theme.Accent)
+	projectTag := colorTag(theme.Project)
+	taskTag := colorTag(theme.Task)
+	dateTag := colorTag(theme.Date)
+	dimTag := colorTag(theme.Dim)
+	const reset = "[-]"
+
+	var lines []recentLine
+	add := func(node *recentNode, format string, args ...any) {
+		lines = append(lines, recentLine{text: fmt.Sprintf(format, args...), node: node})
+	}
+	header := func(node *recentNode, from, labelWidth int, format string, args ...any) {
+		add(node, format, args...)
+		lines[len(lines)-1].header = true
+		lines[len(lines)-1].label = [2]int{from, from + labelWidth}
+	}
+
+	for i, projectNode := range tree {
+		if i > 0 {
+			add(nil, "")
+		}
+		label, summary := "@"+projectNode.project, ""
+		if collapsed[projectNode.id] {
+			n := len(projectNode.children)
+			label = "▸ " + label
+			summary = fmt.Sprintf(" (%d %s)", n, plural(n, "task", "tasks"))
+		}
+		label, summary = fitLabel(label, summary, width-6)
+		lw, sw := runewidth.StringWidth(label), runewidth.StringWidth(summary)
+		// outer top: " ┌ " + label + summary + " " + "─"×n + "┐ "  (width = 3 + lw + sw + 1 + n + 2)
+		header(projectNode, 3, lw, " %s┌ %s%s%s%s%s %s┐%s ", boxTag, projectTag, tview.Escape(label), dimTag, summary, boxTag, strings.Repeat("─", max(0, width-6-lw-sw)), reset)
+
+		for j, taskNode := range projectNode.children {
+			if collapsed[projectNode.id] {
+				break
+			}
+			if j > 0 {
+				// gap line: " │" + spaces + "│ "  (width = 2 + n + 2)
+				add(projectNode, " %s│%s%s%s│%s ", boxTag, reset, strings.Repeat(" ", max(0, width-4)), boxTag, reset)
+			}
+			label, summary := "+"+taskNode.task, ""
+			if collapsed[taskNode.id] {
+				n := len(taskNode.children)
+				label = "▸ " + label
+				summary = fmt.Sprintf(" (%d %s)", n, plural(n, "day", "days"))
+			}
+			label, summary = fitLabel(label, summary, width-10)
+			lw, sw := runewidth.StringWidth(label), runewidth.StringWidth(summary)
+			// inner top: " │ ┌ " + label + summary + " " + "─"×n + "┐ │ "  (width = 5 + lw + sw + 1 + n + 4)
+			header(taskNode, 5, lw, " %s│ ┌ %s%s%s%s%s %s┐ │%s ", boxTag, taskTag, tview.Escape(label), dimTag, summary, boxTag, strings.Repeat("─", max(0, width-10-lw-sw)), reset)
+
+			for _, dateNode := range taskNode.children {
+				if collapsed[taskNode.id] {
+					break
+				}
+				label, summary := dateNode.date.Format(longDate), ""
+				if collapsed[dateNode.id] {
+					n := len(dateNode.entries)
+					label = "▸ " + label
+					summary = fmt.Sprintf(" (%d %s)", n, plural(n, "entry", "entries"))
+				}
+				label, summary = fitLabel(label, summary, width-9)
+				lw, sw := runewidth.StringWidth(label), runewidth.StringWidth(summary)
+				// date line: " │ │ " + label + summary + spaces + "│ │ "  (width = 5 + lw + sw + n + 4)
+				header(dateNode, 5, lw, " %s│ │%s %s%s%s%s%s│ │%s ", boxTag, dateTag, tview.Escape(label), dimTag, summary, strings.Repeat(" ", max(0, width-9-lw-sw)), boxTag, reset)
+				if collapsed[dateNode.id] {
+					continue
+				}
+				for _, entry := range dateNode.entries {
+					content := entry.Content
+					text := reset
+					if isTodo(content) {
+						text = colorTag(theme.Todo)
+					}
+					for _, part := range wrapText(content, width-10, hangingIndent(content)) {
+						cw := runewidth.StringWidth(part)
+						// content line: " │ │  " + content + spaces + "│ │ "  (width = 6 + cw + n + 4)
+						add(dateNode, " %s│ │%s  %s%s%s│ │%s ", boxTag, text, tview.Escape(part), strings.Repeat(" ", max(0, width-10-cw)), boxTag, reset)
+					}
+				}
+			}
+			// inner bottom: " │ └" + "─"×n + "┘ │ "  (width = 4 + n + 4)
+			add(taskNode, " %s│ └%s┘ │%s ", boxTag, strings.Repeat("─", max(0, width-8)), reset)
+		}
+		// outer bottom: " └" + "─"×n + "┘ "  (width = 2 + n + 2)
+		add(projectNode, " %s└%s┘%s ", boxTag, strings.Repeat("─", max(0, width-4)), reset)
+	}
+	return lines
+}
+
+// fitLabel shortens a box title to at most width cells, dropping the summary
+// before truncating the label itself.
+func fitLabel(label, summary string, width int) (string, string) {
+	if runewidth.StringWidth(label+summary) <= width {
+		return label, summary
+	}
+	if runewidth.StringWidth(label) <= width {
+		return label, ""
+	}
+	return runewidth.Truncate(label, max(1, width), "…"), ""
+}
+
+// hangingIndent is how far wrapped lines of an entry are indented so they
+// line up after its bullet, e.g. 2 for "* text".
+func hangingIndent(content string) int {
+	trimmed := strings.TrimLeft(content, " \t")
+	indent := len(content) - len(trimmed)
+	if len(trimmed) > 1 && strings.ContainsRune("*-", rune(trimmed[0])) && trimmed[1] == ' ' {
+		indent += 2
+	}
+	return indent
+}
+
+// wrapText breaks s into lines at most width cells wide, at spaces where it
+// can and mid-word where it must. Continuation lines start with indent
+// spaces, capped at a quarter of the width.
+func wrapText(s string, width, indent int) []string {
+	if runewidth.StringWidth(s) <= width {
+		return []string{s}
+	}
+	indent = min(indent, width/4)
+	prefix := strings.Repeat(" ", indent)
+
+	var lines []string
+	line, lineWidth := "", 0
+	for _, word := range strings.SplitAfter(s, " ") {
+		// Trailing spaces may hang past the edge; they are trimmed below.
+		wordWidth := runewidth.StringWidth(strings.TrimRight(word, " "))
+		// Start a new line if the word fits on one; longer words (or a run
+		// of CJK text) are split from where the current line leaves off.
+		if lineWidth+wordWidth > width && indent+wordWidth <= width && strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " "))
+			line, lineWidth = prefix, indent
+		}
+		for lineWidth+wordWidth > width {
+			head := runewidth.Truncate(word, width-lineWidth, "")
+			if head == "" {
+				switch {
+				case strings.TrimSpace(line) != "": // full, or no room for a wide rune
+					lines = append(lines, strings.TrimRight(line, " "))
+					line, lineWidth = prefix, indent
+					continue
+				case lineWidth > 0: // the indent leaves no room
+					line, lineWidth = "", 0
+					continue
+				}
+				// Not even one rune fits, so it gets a line to itself.
+				_, size := utf8.DecodeRuneInString(word)
+				head = word[:size]
+			}
+			lines = append(lines, line+head)
+			word = word[len(head):]
+			wordWidth = runewidth.StringWidth(strings.TrimRight(word, " "))
+			line, lineWidth = prefix, indent
+		}
+		line += word
+		lineWidth += runewidth.StringWidth(word)
+	}
+	if strings.TrimSpace(line) != "" {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// renderRecentBoxes renders every project/task box of the last days days,
+// fully expanded, sized to width (the content area column count, i.e.
+// terminal width minus the surrounding grid borders).
+func renderRecentBoxes(days int, data *TrailData, width int) string {
+	lines := layoutRecentBoxes(buildRecentTree(days, data), width, nil)
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// --- recentView ---
+
+// recentView draws the recent boxes with a cursor on the box headers.
+// Projects, tasks and dates can be collapsed to their header line.
+type recentView struct {
+	*tview.Box
+	tree      []*recentNode
+	collapsed map[string]bool // by node id; survives changes to the window
+	lines     []recentLine    // laid out for width; nil when stale
+	width     int
+	cursor    string // id of the selected node
+	offset    int    // first visible line
+	open      func(project, task string)
+}
+
+func newRecentView(open func(project, task string)) *recentView {
+	return &recentView{Box: tview.NewBox(), collapsed: make(map[string]bool), open: open}
+}
+
+func (rv *recentView) setTree(tree []*recentNode) {
+	rv.tree = tree
+	rv.lines = nil
+}
+
+// layout re-renders the lines if the width or the tree changed.
+func (rv *recentView) layout(width int) {
+	if rv.lines == nil || width != rv.width {
+		rv.width = width
+		rv.lines = layoutRecentBoxes(rv.tree, width, rv.collapsed)
+	}
+}
+
+// headers returns the indexes of the header lines, top to bottom.
+func (rv *recentView) headers() []int {
+	var headers []int
+	for i, line := range rv.lines {
+		if line.header {
+			headers = append(headers, i)
+		}
+	}
+	return headers
+}
+
+// selected returns the index into headers() of the cursor. A cursor hidden
+// inside a collapsed box counts as being on that box's header.
+func (rv *recentView) selected(headers []int) int {
+	for node := rv.findNode(rv.cursor); node != nil; node = node.parent {
+		for i, line := range headers {
+			if rv.lines[line].node == node {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// findNode looks a node up by id anywhere in the tree.
+func (rv *recentView) findNode(id string) *recentNode {
+	var find func(nodes []*recentNode) *recentNode
+	find = func(nodes []*recentNode) *recentNode {
+		for _, node := range nodes {
+			if node.id == id {
+				return node
+			}
+			if found := find(node.children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(rv.tree)
+}
+
+// selectedNode returns the node under the cursor, or nil if there is none.
+func (rv *recentView) selectedNode() *recentNode {
+	headers := rv.headers()
+	if len(headers) == 0 {
+		return nil
+	}
+	return rv.lines[headers[rv.selected(headers)]].node
+}
+
+// move shifts the cursor by delta headers.
+func (rv *recentView) move(delta int) {
+	headers := rv.headers()
+	if len(headers) == 0 {
+		return
+	}
+	i := max(0, min(rv.selected(headers)+delta, len(headers)-1))
+	rv.cursor = rv.lines[headers[i]].node.id
+}
+
+// jumpProject moves the cursor to the next (dir 1) or previous (dir -1)
+// project box. Going back from inside a project lands on its own header.
+func (rv *recentView) jumpProject(dir int) {
+	headers := rv.headers()
+	if len(headers) == 0 {
+		return
+	}
+	current := rv.selected(headers)
+	for i := current + dir; i >= 0 && i < len(headers); i += dir {
+		if node := rv.lines[headers[i]].node; node.isProject() {
+			rv.cursor = node.id
+			return
+		}
+	}
+}
+
+// toggle collapses or expands the box under the cursor.
+func (rv *recentView) toggle() {
+	if node := rv.selectedNode(); node != nil {
+		rv.setCollapsed(node, !rv.collapsed[node.id])
+	}
+}
+
+func (rv *recentView) setCollapsed(node *recentNode, collapsed bool) {
+	if collapsed {
+		rv.collapsed[node.id] = true
+	} else {
+		delete(rv.collapsed, node.id)
+	}
+	rv.cursor = node.id
+	rv.lines = nil
+	rv.layout(rv.width)
+}
+
+// activate handles Enter: tasks and projects open their full history, dates
+// collapse or expand.
+func (rv *recentView) activate() {
+	node := rv.selectedNode()
+	switch {
+	case node == nil:
+	case node.isDate():
+		rv.toggle()
+	case rv.open != nil:
+		rv.open(node.project, node.task)
+	}
+}
+
+func (rv *recentView) Draw(screen tcell.Screen) {
+	rv.DrawForSubclass(screen, rv)
+	x, y, width, height := rv.GetInnerRect()
+	rv.layout(width)
+	if len(rv.lines) == 0 || height <= 0 {
+		return
+	}
+
+	// Keep the selected box in view, showing as much of it as fits.
+	headers := rv.headers()
+	i := rv.selected(headers)
+	first := headers[i]
+	last := first
+	for last+1 < len(rv.lines) && rv.lines[first].node.contains(rv.lines[last+1].node) {
+		last++
+	}
+	if i == len(headers)-1 {
+		last = len(rv.lines) - 1
+	}
+	rv.offset = max(rv.offset, min(last, first+height-1)-height+1)
+	rv.offset = max(0, min(rv.offset, first, len(rv.lines)-height))
+
+	for row := 0; row < height && rv.offset+row < len(rv.lines); row++ {
+		line := rv.lines[rv.offset+row]
+		tview.Print(screen, line.text, x, y+row, width, tview.AlignLeft, theme.Text)
+		if rv.offset+row == first && rv.HasFocus() {
+			for cx := x + line.label[0]; cx < x+line.label[1]; cx++ {
+				mainc, combc, style, _ := screen.GetContent(cx, y+row)
+				screen.SetContent(cx, y+row, mainc, combc, style.Reverse(true))
+			}
+		}
+	}
+}
+
+func (rv *recentView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return rv.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		_, _, _, height := rv.GetInnerRect()
+		switch event.Key() {
+		case tcell.KeyUp:
+			rv.move(-1)
+		case tcell.KeyDown:
+			rv.move(1)
+		case tcell.KeyPgUp:
+			rv.move(-max(1, height/2))
+		case tcell.KeyPgDn:
+			rv.move(max(1, height/2))
+		case tcell.KeyHome:
+			rv.move(-len(rv.lines))
+		case tcell.KeyEnd:
+			rv.move(len(rv.lines))
+		case tcell.KeyLeft:
+			// Collapse, or step out to the enclosing box if already collapsed.
+			if node := rv.selectedNode(); node != nil {
+				if !rv.collapsed[node.id] {
+					rv.setCollapsed(node, true)
+				} else if node.parent != nil {
+					rv.cursor = node.parent.id
+				}
+			}
+		case tcell.KeyRight:
+			if node := rv.selectedNode(); node != nil && rv.collapsed[node.id] {
+				rv.setCollapsed(node, false)
+			}
+		case tcell.KeyEnter:
+			rv.activate()
+		}
+	})
+}
+
+// MouseHandler selects the box whose header was clicked. Clicking a project
+// or task name opens it, like Enter; clicking a date toggles it.
+func (rv *recentView) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return rv.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		x, y := event.Position()
+		if !rv.InRect(x, y) {
+			return false, nil
+		}
+		rectX, rectY, _, _ := rv.GetInnerRect()
+		switch action {
+		case tview.MouseLeftDown:
+			setFocus(rv)
+		case tview.MouseLeftClick:
+			row := rv.offset + y - rectY
+			if row < 0 || row >= len(rv.lines) || !rv.lines[row].header {
+				break
+			}
+			line := rv.lines[row]
+			rv.cursor = line.node.id
+			if col := x - rectX; col >= line.label[0] && col < line.label[1] {
+				rv.activate()
+			}
+		case tview.MouseScrollUp:
+			rv.move(-1)
+		case tview.MouseScrollDown:
+			rv.move(1)
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+}
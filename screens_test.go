@@ -0,0 +1,872 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestTabCyclesScreens(t *testing.T) {
+	h := newHarness(t, 100, 30, "pinned")
+
+	// Each screen, with something only it shows.
+	screens := []struct{ name, shows string }{
+		{"pinned", "Nothing pinned yet."},
+		{"projects", "Filter Projects:"},
+		{"tasks", "Filter Tasks:"},
+		{"days", "Filter Dates:"},
+		{"week", "2025-W07"},
+		{"calendar", "Mon"},
+		{"recent", "Last N days:"},
+		{"timeline", "H/L first/last"},
+		{"stats", "Longest streak"},
+		{"authors", "Filter Authors:"},
+	}
+	for _, screen := range screens {
+		if h.ui.current != screen.name {
+			t.Fatalf("on %q, want %q", h.ui.current, screen.name)
+		}
+		h.contains(screen.shows)
+		h.press("Tab")
+	}
+	if h.ui.current != "pinned" {
+		t.Fatalf("Tab from the last screen went to %q, want pinned", h.ui.current)
+	}
+	h.press("Backtab")
+	h.contains("Filter Authors:")
+}
+
+func TestStatusLine(t *testing.T) {
+	h := newHarness(t, 120, 20, "projects")
+	last := h.lines()[19]
+	for _, want := range []string{"testdata/notes", "6 files", "4 projects", "12 entries", "1 warning"} {
+		if !strings.Contains(last, want) {
+			t.Errorf("status line %q lacks %q", last, want)
+		}
+	}
+}
+
+func TestProjectsScreen(t *testing.T) {
+	h := newHarness(t, 80, 24, "projects")
+	if got := h.selected(); got != "archive" {
+		t.Fatalf("selected %q, want archive", got)
+	}
+
+	h.press("j")
+	if got := h.selected(); got != "garden" {
+		t.Fatalf("after j selected %q, want garden", got)
+	}
+	h.press("Enter")
+	h.contains("beds", "tools")
+	h.lacks("archive")
+
+	h.press("Enter")
+	h.contains("25-02-14", "- turned the compost", "25-02-13", "- planned the spring beds")
+
+	h.press("Esc")
+	h.contains("beds", "tools")
+	h.press("Esc")
+	h.contains("archive", "garden", "someday", "trail")
+
+	h.press("k")
+	if got := h.selected(); got != "archive" {
+		t.Fatalf("after k selected %q, want archive", got)
+	}
+
+	h.press("/")
+	h.typeText("gd")
+	h.contains("garden")
+	h.lacks("archive", "someday", "trail")
+	h.press("Esc")
+	h.press("Enter")
+	h.contains("beds", "tools")
+}
+
+func TestTasksScreen(t *testing.T) {
+	h := newHarness(t, 80, 24, "tasks")
+	h.contains("archive/old-task", "garden/beds", "trail/general", "trail/parser", "trail/ui")
+
+	h.press("/")
+	h.typeText("parser")
+	h.contains("trail/parser")
+	h.lacks("garden/beds")
+
+	h.press("Enter") // leave the filter for the list
+	h.press("Enter") // open the task
+	h.contains("- moved the parser into its own package", "- added line numbers to entries")
+	text := h.text()
+	if strings.Index(text, "25-02-14") > strings.Index(text, "25-02-13") {
+		t.Fatalf("entries are not newest first:\n%s", text)
+	}
+
+	h.press("Esc")
+	h.contains("trail/parser")
+	h.lacks("moved the parser")
+}
+
+func TestDefaultTask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	for _, test := range []struct {
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{``, "general", false},
+		{`default_task = "misc"`, "misc", false},
+		{`default_task = ""`, "", false},
+		{`default_task = "odds and ends"`, "", true},
+	} {
+		if err := os.WriteFile(path, []byte(test.config+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadConfig(path, true)
+		if test.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "default_task") {
+				t.Errorf("%q: error = %v, want one about default_task", test.config, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", test.config, err)
+		}
+		if cfg.DefaultTask != test.want {
+			t.Errorf("%q: default task = %q, want %q", test.config, cfg.DefaultTask, test.want)
+		}
+	}
+
+	// Turned off, the project-only heading is reported and skipped.
+	h := newHarness(t, 100, 24, "tasks", func(cfg *Config) {
+		cfg.DefaultTask, cfg.defaultTaskSet = "", true
+	})
+	h.contains("trail/parser", "2 warnings")
+	h.lacks("trail/general")
+}
+
+// TestConfigErrors checks that every invalid setting is reported with the
+// path of the field at fault.
+func TestConfigErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	for _, test := range []struct {
+		config, want string
+	}{
+		{`wrap = true`, "unknown key wrap"},
+		{"[dates]\nweekday = \"Mon\"", "unknown key dates.weekday"},
+		{`notes_dirs = ["testdata/missing"]`, "notes_dirs: stat testdata/missing"},
+		{`notes_dirs = ["screens_test.go"]`, "notes_dirs: screens_test.go is not a directory"},
+		{`default_screen = "inbox"`, `default_screen: unknown screen "inbox"`},
+		{`recent_days = -3`, "recent_days: must be positive, got -3"},
+		{"[dates]\nlong = \"Jan 2\"", `dates.long: "Jan 2" is not a Go date layout`},
+		{"[dates]\nshort = \"yesterday\"", `dates.short: "yesterday" is not a Go date layout`},
+		{"[dates]\nfilename = \"Jan-02-2006\"", `dates.filename: "Jan-02-2006" must be numeric`},
+		{`theme = "solarized"`, `theme: unknown theme "solarized"`},
+		{"[colors]\nsky = \"blue\"", "colors.sky: unknown colour name"},
+		{"[colors]\naccent = \"sky-blue\"", `colors.accent: invalid colour "sky-blue"`},
+		{"[keys]\njump = \"J\"", "keys.jump: unknown action"},
+		{"[keys]\ntop = \"gg\"", `keys.top: unknown key "gg"`},
+	} {
+		if err := os.WriteFile(path, []byte(test.config+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := loadConfig(path, true)
+		if want := path + ": " + test.want; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error = %v, want %s", test.config, err, want)
+		}
+	}
+}
+
+func TestDaysScreen(t *testing.T) {
+	h := newHarness(t, 80, 24, "days")
+	h.contains("2025-02-14", "2025-02-13", "2025-02-10", "2025-01-20", "2024-11-05")
+	if got := h.selected(); got != "2025-02-14" {
+		t.Fatalf("selected %q, want the newest day", got)
+	}
+
+	h.press("j")
+	h.press("Enter")
+	h.contains("@trail", "+i18n", "翻訳を見直した", "@garden", "+beds")
+	h.lacks("turned the compost")
+
+	h.press("Esc")
+	h.press("/")
+	h.typeText("01-20")
+	h.contains("2025-01-20")
+	h.lacks("2025-02-14")
+}
+
+func TestWeekScreen(t *testing.T) {
+	h := newHarness(t, 140, 30, "week")
+	h.contains("2025-W07", "2025-02-10 – 2025-02-16", "Mon 02-10", "- sketched", "- turned the")
+
+	h.press("]")
+	h.contains("2025-W08")
+	h.lacks("turned the")
+
+	h.press("t")
+	h.contains("2025-W07")
+
+	h.press("[ [ [")
+	h.contains("2025-W04", "- sharpened")
+}
+
+func TestCalendarScreen(t *testing.T) {
+	h := newHarness(t, 120, 30, "calendar")
+	h.contains("- turned the compost")
+
+	h.press("k") // the day before
+	h.contains("- added line numbers to entries")
+	h.lacks("turned the compost")
+
+	h.press("Enter")
+	if h.ui.current != "days" {
+		t.Fatalf("Enter opened %q, want days", h.ui.current)
+	}
+	h.contains("- added line numbers to entries", "+i18n")
+}
+
+// TestCalendarPaletteTheme checks that a theme of palette colours draws the
+// heatmap in palette colours too, for terminals without true colour.
+func TestCalendarPaletteTheme(t *testing.T) {
+	h := newHarness(t, 120, 30, "calendar", func(cfg *Config) { cfg.Theme = "basic" })
+	h.sync()
+	cells, width, _ := h.screen.GetContents()
+	for i, cell := range cells {
+		fg, bg, _ := cell.Style.Decompose()
+		if fg.IsRGB() || bg.IsRGB() {
+			t.Fatalf("cell %d,%d %q is drawn in true colour", i%width, i/width, cell.Runes)
+		}
+	}
+	if colors := heatmapColors(); colors != paletteHeatmap {
+		t.Errorf("heatmap colours = %v, want the palette ramp", colors)
+	}
+}
+
+// TestThemeKeepsState switches theme in place: the screen keeps its filter
+// and selection, and no screen is left in the old theme's colours.
+func TestThemeKeepsState(t *testing.T) {
+	h := newHarness(t, 100, 24, "tasks")
+	h.press("/")
+	h.typeText("trail")
+	h.press("Enter j")
+	want := h.selected()
+	if want == "" {
+		t.Fatal("nothing selected")
+	}
+
+	h.press("T T T") // light, high contrast, then basic
+	if theme.Name != "basic" {
+		t.Fatalf("theme is %q, want basic", theme.Name)
+	}
+	if got := h.selected(); got != want {
+		t.Fatalf("selected %q, want %q", got, want)
+	}
+	h.contains("Filter Tasks: trail")
+
+	for range screenNames {
+		cells, width, _ := h.screen.GetContents()
+		for i, cell := range cells {
+			fg, bg, _ := cell.Style.Decompose()
+			if fg.IsRGB() || bg.IsRGB() {
+				t.Fatalf("%s: cell %d,%d %q is in the old theme's colours", h.ui.current, i%width, i/width, cell.Runes)
+			}
+		}
+		h.press("Tab")
+	}
+}
+
+// TestYank copies what each screen has selected as markdown and says what
+// was copied in the status line.
+func TestYank(t *testing.T) {
+	const day = "## 2025-02-14\n\n" +
+		"### @garden +beds\n\n- turned the compost\n\n" +
+		"### @trail +parser\n\n- moved the parser into its own package\n- TODO benchmark the worker pool\n\n" +
+		"### @trail +ui\n\n* drew the tab bar\n  and made the tabs clickable\n"
+	const oldTask = "## @archive +old-task\n\n### 2024-11-05\n\n- wrapped up last year's work\n"
+	for _, test := range []struct {
+		screen    string
+		keys      string
+		configure []func(*Config)
+		flash     string
+		want      string
+	}{
+		{screen: "pinned", flash: "nothing to copy"},
+		{screen: "tasks", keys: "p Shift-Tab Shift-Tab", flash: "copied pinned entries", want: oldTask},
+		{screen: "projects", flash: "copied @archive", want: oldTask},
+		{screen: "projects", keys: "j Enter j", flash: "copied @garden +tools",
+			want: "## @garden +tools\n\n### 2025-01-20\n\n- sharpened the shears\n"},
+		{screen: "tasks", flash: "copied @archive +old-task", want: oldTask},
+		{screen: "days", flash: "copied 2025-02-14", want: day},
+		{screen: "week", flash: "copied 2025-W07",
+			want: "## 2025-02-10\n\n" +
+				"### @trail +general\n\n- a heading without a task\n\n" +
+				"### @trail +ui\n\n- sketched the screens\n\n" +
+				"## 2025-02-13\n\n" +
+				"### @garden +beds\n\n- planned the spring beds, with a long note about which seeds go where and when to sow them\n\n" +
+				"### @trail +i18n\n\n- 翻訳を見直した、長い文章も折り返すべき 🎌\n\n" +
+				"### @trail +parser\n\n- added line numbers to entries\n\n" +
+				day},
+		{screen: "calendar", flash: "copied 2025-02-14", want: day},
+		{screen: "recent", flash: "copied @garden",
+			want: "## @garden +beds\n\n" +
+				"### 2025-02-14\n\n- turned the compost\n\n" +
+				"### 2025-02-13\n\n- planned the spring beds, with a long note about which seeds go where and when to sow them\n\n" +
+				"## @garden +tools\n\n### 2025-01-20\n\n- sharpened the shears\n"},
+		{screen: "recent", keys: "j j", flash: "copied @garden +beds 2025-02-14",
+			want: "## @garden +beds\n\n### 2025-02-14\n\n- turned the compost\n"},
+		{screen: "timeline", flash: "copied @archive", want: oldTask},
+		{screen: "authors", configure: []func(*Config){withPeople}, flash: "copied alice's entries",
+			want: "## @ops +deploy\n\n### 2025-02-14\n\n- shipped the release\n\n" +
+				"## @trail +parser\n\n" +
+				"### 2025-02-14\n\n- reviewed the authors screen\n\n" +
+				"### 2025-02-13\n\n- read author names from directories\n"},
+	} {
+		t.Run(test.screen+" "+test.keys, func(t *testing.T) {
+			h := newHarness(t, 100, 24, test.screen, test.configure...)
+			if test.keys != "" {
+				h.press(test.keys)
+			}
+			h.press("y")
+			h.contains(test.flash)
+			if got := h.clipboard(); got != test.want {
+				t.Errorf("copied %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	// The stats are copied as shown, in a code block so the bars line up.
+	h := newHarness(t, 100, 24, "stats")
+	h.press("y")
+	h.contains("copied stats")
+	got := h.clipboard()
+	if !strings.HasPrefix(got, "```\nWindow  2025-02-08 – 2025-02-14 (7 days)\n") || !strings.HasSuffix(got, "(101 days ago)\n```\n") ||
+		!strings.Contains(got, "\n  trail/parser   ███") || strings.Contains(got, "[") {
+		t.Errorf("copied stats %q", got)
+	}
+}
+
+func TestOSC52(t *testing.T) {
+	const plain = "\x1b]52;c;QHRyYWlsICtwYXJzZXI=\a"
+	for _, test := range []struct {
+		name, tmux, sty, want string
+	}{
+		{name: "plain", want: plain},
+		{name: "tmux", tmux: "/tmp/tmux-1000/default,1,0",
+			want: plain + "\x1bPtmux;\x1b\x1b]52;c;QHRyYWlsICtwYXJzZXI=\a\x1b\\"},
+		{name: "screen", sty: "1234.pts-0.host", want: "\x1bP" + plain + "\x1b\\"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("TMUX", test.tmux)
+			t.Setenv("STY", test.sty)
+			if got := osc52("@trail +parser"); got != test.want {
+				t.Errorf("osc52 = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestPinSet pins and unpins tasks, reading pins.txt back after every
+// change to check it was saved.
+func TestPinSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.txt")
+	ps, err := loadPins(path)
+	if err != nil || len(ps.keys) != 0 {
+		t.Fatalf("loadPins before any pins = %v, %v; want nothing pinned", ps.keys, err)
+	}
+	for _, test := range []struct {
+		project, task string
+		pinned        bool
+		keys          []string
+	}{
+		{"trail", "parser", true, []string{"trail/parser"}},
+		{"garden", "beds", true, []string{"trail/parser", "garden/beds"}},
+		{"trail", "parser", false, []string{"garden/beds"}},
+		{"trail", "ui", true, []string{"garden/beds", "trail/ui"}},
+	} {
+		pinned, err := ps.toggle(test.project, test.task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pinned != test.pinned || ps.has(test.project, test.task) != test.pinned {
+			t.Errorf("toggle(%s, %s) pinned = %v, want %v", test.project, test.task, pinned, test.pinned)
+		}
+		loaded, err := loadPins(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(loaded.keys, test.keys) {
+			t.Errorf("after toggle(%s, %s) pins.txt has %q, want %q", test.project, test.task, loaded.keys, test.keys)
+		}
+	}
+}
+
+// TestPinnedFirst pins a task and finds it, and its project, at the top of
+// the lists.
+func TestPinnedFirst(t *testing.T) {
+	h := newHarness(t, 100, 24, "tasks")
+	h.press("G p")
+	h.contains("pinned @trail +ui")
+	if got := h.selected(); got != "★ trail/ui" {
+		t.Fatalf("selected %q after pinning, want ★ trail/ui", got)
+	}
+	h.press("g g")
+	if got := h.selected(); got != "★ trail/ui" {
+		t.Fatalf("top of the tasks is %q, want ★ trail/ui", got)
+	}
+
+	h.press("Shift-Tab g g") // projects, which kept @archive selected
+	if got := h.selected(); got != "★ trail" {
+		t.Fatalf("top of the projects is %q, want ★ trail", got)
+	}
+	h.press("Enter")
+	if got := h.selected(); got != "★ ui" {
+		t.Fatalf("top of @trail's tasks is %q, want ★ ui", got)
+	}
+
+	h.press("p") // unpin
+	h.contains("unpinned @trail +ui")
+	h.lacks("★")
+	h.press("Shift-Tab") // pinned
+	h.contains("Nothing pinned yet.")
+}
+
+// TestKeyBindings drives the tasks list with keys remapped in the config.
+func TestKeyBindings(t *testing.T) {
+	h := newHarness(t, 100, 30, "tasks", func(cfg *Config) {
+		cfg.Keys = map[string]keyBinding{"down": {"n", "Down"}, "top": {"z z"}}
+	})
+	selected := func(want string) {
+		t.Helper()
+		if got := h.selected(); got != want {
+			t.Fatalf("selected %q, want %q", got, want)
+		}
+	}
+
+	h.press("n n")
+	selected("garden/tools")
+	h.press("j") // replaced by n
+	selected("garden/tools")
+	h.press("G g g") // so is g g
+	selected("trail/ui")
+	h.press("z z")
+	selected("archive/old-task")
+
+	// A key that doesn't go on with the sequence drops it and counts on
+	// its own, so the next z starts over.
+	h.press("z n z n")
+	selected("garden/tools")
+
+	// So does a sequence whose next key comes too late.
+	h.press("G z")
+	h.app.QueueUpdateDraw(func() {
+		now = func() time.Time { return fixtureNow.Add(keySeqTimeout + time.Millisecond) }
+	})
+	h.press("z")
+	selected("trail/ui")
+	h.press("z")
+	selected("archive/old-task")
+
+	h.press("?")
+	h.contains("n, Down ", " move down", "z z ", " jump to top")
+	h.lacks("j, Down", "g g")
+	h.press("Esc")
+
+	// Letters typed into the filter are text, not bindings.
+	h.press("/")
+	h.typeText("garden")
+	h.contains("Filter Tasks: garden", "garden/beds", "garden/tools")
+	h.lacks("trail/ui")
+}
+
+func TestParseKeySeq(t *testing.T) {
+	for _, test := range []struct {
+		in, want, err string
+	}{
+		{in: "g g", want: "g g"},
+		{in: "ctrl-d", want: "Ctrl-D"},
+		{in: "Ctrl-W l", want: "Ctrl-W l"},
+		{in: "space", want: "Space"},
+		{in: "Shift-Tab", want: "Backtab"},
+		{in: "", err: "empty key binding"},
+		{in: "  ", err: "empty key binding"},
+		{in: "gg", err: `unknown key "gg"`},
+		{in: "g Ctrl-", err: `unknown key "Ctrl-"`},
+		{in: "F99", err: `unknown key "F99"`},
+	} {
+		seq, err := parseKeySeq(test.in)
+		switch {
+		case test.err != "":
+			if err == nil || err.Error() != test.err {
+				t.Errorf("parseKeySeq(%q) error = %v, want %s", test.in, err, test.err)
+			}
+		case err != nil:
+			t.Errorf("parseKeySeq(%q): %v", test.in, err)
+		case seq.String() != test.want:
+			t.Errorf("parseKeySeq(%q) = %q, want %q", test.in, seq, test.want)
+		}
+	}
+}
+
+func TestRecentScreen(t *testing.T) {
+	h := newHarness(t, 80, 40, "recent")
+	h.contains("┌ @garden", "┌ +beds", "┌ +tools", "┌ @trail", "┌ +parser", "- turned the compost")
+	h.lacks("@archive") // older than the 28 day window
+
+	h.press("Space") // collapse @garden
+	h.contains("▸ @garden (2 tasks)")
+	h.lacks("turned the compost")
+	h.press("Space")
+	h.contains("turned the compost")
+
+	h.press("/")
+	h.press("Backspace Backspace")
+	h.typeText("120")
+	h.press("Enter Home") // back to the boxes, at the top
+	h.contains("┌ @archive", "- wrapped up last year's work")
+
+	h.press("] ] j j j") // the @trail box, +general and its day, then +i18n
+	h.press("Enter")
+	if h.ui.current != "tasks" {
+		t.Fatalf("Enter opened %q, want tasks", h.ui.current)
+	}
+	h.contains("翻訳を見直した")
+}
+
+// TestRecentBoxes lays the recent boxes out at several widths and compares
+// them with the golden files in testdata/recent. Run the tests with -update
+// after an intended layout change to rewrite them.
+func TestRecentBoxes(t *testing.T) {
+	h := newHarness(t, 80, 24, "recent")
+	for _, width := range []int{24, 40, 60, 100} {
+		got := stripColorTags(renderRecentBoxes(28, h.ui.data, width))
+		for i, line := range strings.Split(got, "\n") {
+			// Blank lines separate the project boxes.
+			if w := runewidth.StringWidth(line); line != "" && w != width {
+				t.Errorf("width %d: line %d is %d cells wide: %q", width, i+1, w, line)
+			}
+		}
+
+		golden := filepath.Join("testdata", "recent", fmt.Sprintf("width-%d.txt", width))
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(golden, []byte(got+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got+"\n" != string(want) {
+			t.Errorf("width %d differs from %s:\n%s", width, golden, got)
+		}
+	}
+}
+
+// TestWrapText checks that no wrapped line is wider than asked, whatever
+// mix of long words and wide runes it has to break.
+func TestWrapText(t *testing.T) {
+	texts := []string{
+		"- abcdefghij https://example.com/a/very/long/url/that/does/not/fit",
+		"* see https://example.com/docs?page=1&section=2 and https://example.org/x",
+		"- 翻訳を見直した、用語集も更新した。次は索引を作り直す予定です",
+		"- abcdefghi 翻訳を見直した https://example.com/翻訳/見直し",
+		"  indented https://example.com/a/very/long/url",
+	}
+	for _, text := range texts {
+		for width := 1; width <= 30; width++ {
+			lines := wrapText(text, width, hangingIndent(text))
+			for _, line := range lines {
+				// A rune wider than the line is all that can overflow it.
+				if w := runewidth.StringWidth(line); w > width && utf8.RuneCountInString(line) > 1 {
+					t.Errorf("width %d: %q wraps to a line %d cells wide: %q", width, text, w, line)
+				}
+			}
+			joined := strings.ReplaceAll(strings.Join(lines, ""), " ", "")
+			if want := strings.ReplaceAll(text, " ", ""); joined != want {
+				t.Errorf("width %d: %q wraps to %q, losing text", width, text, lines)
+			}
+		}
+	}
+}
+
+// TestRecentScreenWidths checks that the boxes on screen keep their right
+// borders lined up however wide the terminal is.
+func TestRecentScreenWidths(t *testing.T) {
+	for _, width := range []int{30, 50, 80, 120} {
+		h := newHarness(t, width, 40, "recent")
+		lines := h.lines()
+		for y, line := range lines[4 : len(lines)-2] {
+			if line == "" {
+				continue
+			}
+			if runewidth.StringWidth(line) != width || !strings.HasSuffix(line, "│") {
+				t.Errorf("width %d: row %d is not flush with the border:\n%s", width, y+4, h.text())
+				break
+			}
+		}
+	}
+}
+
+func TestTimelineScreen(t *testing.T) {
+	h := newHarness(t, 100, 20, "timeline")
+	h.contains("@archive", "@garden", "@trail")
+	h.lacks("+parser")
+
+	h.press("j j Enter") // expand @trail
+	h.contains("+parser", "+ui", "+i18n")
+	h.press("k")
+	h.press("Enter")
+	h.contains("+tools", "+beds")
+}
+
+func TestStatsScreen(t *testing.T) {
+	h := newHarness(t, 100, 40, "stats")
+	h.contains("2025-02-08 – 2025-02-14 (7 days)", "Active days      3 / 7", "Most touched     @trail/+parser (3 entries)", "trail/general  ")
+
+	h.press("/")
+	h.press("Backspace")
+	h.typeText("100")
+	h.contains("2024-11-07 – 2025-02-14 (100 days)", "Active days      4 / 100")
+}
+
+// withTeam reads the team notes as well as the personal ones.
+func withTeam(cfg *Config) {
+	cfg.NotesDirs = []string{"testdata/notes", "testdata/team"}
+}
+
+func TestSources(t *testing.T) {
+	h := newHarness(t, 100, 24, "tasks", withTeam)
+	h.contains("ops/deploy", "trail/parser", "testdata/notes, testdata/team", "7 files", "5 projects")
+
+	h.press("/")
+	h.typeText("trail/parser")
+	h.press("Enter Enter")
+	h.contains("25-02-14 · notes", "- moved the parser", "25-02-14 · team", "- reviewed the parser package")
+
+	// The status line is busy saying which source is shown, so check what
+	// it will go back to.
+	status := func() string { return renderStatus(h.ui.data, h.ui.shownSources()) }
+
+	h.press("s") // the personal notes only, with the task still open
+	h.contains("showing notes only", "25-02-14", "- moved the parser")
+	h.lacks("reviewed the parser", "· notes")
+	if got := status(); !strings.Contains(got, "6 files") || strings.Contains(got, "testdata/team") {
+		t.Errorf("status %q is not for the personal notes alone", got)
+	}
+
+	h.press("s") // the team notes only
+	h.contains("showing team only", "- reviewed the parser package")
+	h.lacks("moved the parser")
+	if got := status(); !strings.Contains(got, "1 file ") || !strings.Contains(got, "2 projects") {
+		t.Errorf("status %q is not for the team notes alone", got)
+	}
+
+	h.press("Esc /") // clear the filter
+	h.press(strings.Repeat("Backspace ", len("trail/parser")) + "Enter")
+	h.contains("ops/deploy", "trail/parser")
+	h.lacks("garden/beds")
+
+	h.press("s") // both again
+	h.contains("showing every source", "ops/deploy", "garden/beds")
+}
+
+// TestReloadError keeps the notes on screen when they can no longer be
+// read, rather than exiting with the terminal still in raw mode.
+func TestReloadError(t *testing.T) {
+	h := newHarness(t, 100, 24, "tasks")
+	gone := filepath.Join(t.TempDir(), "gone")
+	h.app.QueueUpdateDraw(func() {
+		h.ui.cfg.sources[0].fsys = os.DirFS(gone)
+		h.ui.reload()
+	})
+	h.sync()
+	h.contains("reload failed", "trail/parser", "garden/beds")
+}
+
+// TestSourcesUnclean filters sources given as paths that aren't clean,
+// which still have to match the files and warnings read from them.
+func TestSourcesUnclean(t *testing.T) {
+	h := newHarness(t, 100, 24, "tasks", func(cfg *Config) {
+		cfg.NotesDirs = []string{"./testdata/notes", "testdata/team/"}
+	})
+	h.contains("7 files", "5 projects", "1 warning")
+	status := func() string { return renderStatus(h.ui.data, h.ui.shownSources()) }
+
+	h.press("s")
+	for _, want := range []string{"testdata/notes ·", "6 files", "4 projects", "12 entries", "1 warning"} {
+		if got := status(); !strings.Contains(got, want) {
+			t.Errorf("personal notes status %q lacks %q", got, want)
+		}
+	}
+	h.press("s")
+	for _, want := range []string{"testdata/team ·", "1 file ", "2 projects"} {
+		if got := status(); !strings.Contains(got, want) {
+			t.Errorf("team notes status %q lacks %q", got, want)
+		}
+	}
+	if got := status(); strings.Contains(got, "warning") {
+		t.Errorf("team notes status %q has the personal notes' warning", got)
+	}
+}
+
+func TestSourcesDeduplicated(t *testing.T) {
+	abs, err := filepath.Abs("testdata/notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHarness(t, 100, 24, "tasks", func(cfg *Config) {
+		cfg.NotesDirs = []string{"testdata/notes", abs, "testdata/../testdata/notes/"}
+	})
+	if got := h.ui.cfg.NotesDirs; !slices.Equal(got, []string{"testdata/notes"}) {
+		t.Errorf("notes dirs = %q, want testdata/notes once", got)
+	}
+	h.contains("6 files", "12 entries")
+}
+
+func TestSeparateProjects(t *testing.T) {
+	h := newHarness(t, 100, 24, "projects", withTeam, func(cfg *Config) {
+		cfg.SeparateProjects = true
+	})
+	h.contains("notes:trail", "team:trail", "garden", "ops", "6 projects")
+	h.lacks("│trail")
+}
+
+// withPeople reads team notes kept in a directory per person.
+func withPeople(cfg *Config) {
+	cfg.NotesDirs = []string{"testdata/people"}
+	cfg.AuthorDirs = true
+}
+
+// TestHighlightMatchesEscapes checks that names which look like color tags
+// show as written in filtered lists.
+func TestHighlightMatchesEscapes(t *testing.T) {
+	view := tview.NewTextView().SetDynamicColors(true)
+	for _, positions := range [][]int{nil, {0}, {1}, {1, 2, 3}, {5}} {
+		view.SetText(highlightMatches("[red]x", positions))
+		if got := view.GetText(true); got != "[red]x" {
+			t.Errorf("positions %v: shows %q, want [red]x", positions, got)
+		}
+	}
+}
+
+func TestAuthorsScreen(t *testing.T) {
+	h := newHarness(t, 100, 24, "authors", withPeople)
+	if got := h.selected(); got != "alice" {
+		t.Fatalf("selected %q, want alice", got)
+	}
+	h.contains("bob", "4 files")
+
+	h.press("Enter")
+	h.contains("alice  3 entries on 2 days, 2025-02-13 – 2025-02-14", "@ops", "+deploy  1 entry, 25-02-14", "@trail", "+parser  2 entries, 25-02-13 – 25-02-14")
+	h.lacks("planning")
+
+	h.press("Esc /")
+	h.typeText("bo")
+	h.press("Enter Enter")
+	h.contains("bob  1 entry on 1 day, 2025-02-14", "+parser  1 entry, 25-02-14")
+}
+
+func TestAuthorsInProjects(t *testing.T) {
+	h := newHarness(t, 140, 24, "projects", withPeople)
+	h.press("v j") // preview @trail beside the list
+	h.contains("+parser    3 entries, last 25-02-14, by alice, bob", "+planning  1 entry, last 25-02-12")
+	h.lacks("25-02-12, by")
+
+	h.press("Enter") // preview +parser
+	h.contains("25-02-14 · alice", "- reviewed the authors screen", "25-02-14 · bob", "- fixed the cache key", "25-02-13 · alice")
+
+	h.press("a") // alice only, still on +parser
+	h.contains("showing alice only", "- reviewed the authors screen")
+	h.lacks("fixed the cache key")
+
+	h.press("Esc") // back to the projects, with @trail still highlighted
+	h.contains("ops", "+parser  2 entries, last 25-02-14, by alice")
+	h.lacks("planning")
+
+	h.press("a a") // bob, then everyone
+	h.contains("showing every author", "+planning")
+}
+
+func TestTodayNotePath(t *testing.T) {
+	now = func() time.Time { return fixtureNow }
+	t.Cleanup(func() { now = time.Now })
+	dirs := []string{t.TempDir(), t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(dirs[1], "alice"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(dirs[1], "alice", "standup-25-02-14.md")
+	if err := os.WriteFile(existing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		author, want string
+	}{
+		{"", filepath.Join(dirs[0], "25-02-14.md")},
+		{"alice", existing},
+		{"bob", filepath.Join(dirs[0], "bob", "25-02-14.md")},
+	} {
+		if got := todayNotePath(dirs, test.author); got != test.want {
+			t.Errorf("todayNotePath(%q) = %s, want %s", test.author, got, test.want)
+		}
+	}
+
+	// Late in the evening west of UTC it is already tomorrow for entries,
+	// so it is for today's note too.
+	now = func() time.Time {
+		return time.Date(2025, time.February, 14, 23, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	}
+	if got, want := todayNotePath(dirs, ""), filepath.Join(dirs[0], "25-02-15.md"); got != want {
+		t.Errorf("todayNotePath late on the 14th = %s, want %s", got, want)
+	}
+}
+
+// withFrontMatter reads notes that open with a YAML front matter block.
+func withFrontMatter(cfg *Config) {
+	cfg.NotesDirs = []string{"testdata/frontmatter"}
+}
+
+func TestFrontMatter(t *testing.T) {
+	h := newHarness(t, 100, 24, "tasks", withFrontMatter)
+	h.contains("garden/release", "trail/docs", "trail/release", "trail/review", "3 files", "7 entries", "2 warnings")
+
+	h.press("j j Enter") // trail/release
+	h.contains(
+		"25-02-14", "- the broken front matter above is ignored",
+		"25-02-13", "- announced the release",
+		"25-02-12 · carol · #release #v1", "- tagged v1.0", "- wrote the changelog",
+	)
+	h.lacks("25-02-13 ·")
+
+	var messages []string
+	for _, d := range h.ui.data.Diagnostics {
+		messages = append(messages, d.String())
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{
+		`25-02-13.md:1: front matter key "colour" is not one of`,
+		"25-02-14.md:1: front matter is not valid YAML",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagnostics lack %q:\n%s", want, got)
+		}
+	}
+
+	// carol is only named in front matter, of a note at the top.
+	carol := filterAuthor(*h.ui.data, "carol")
+	if want := []string{filepath.Join("testdata", "frontmatter", "release-notes.md")}; !slices.Equal(carol.Files, want) {
+		t.Errorf("carol's files = %q, want %q", carol.Files, want)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// splitLayout turns on the side-by-side list and preview layout. It is
+// toggled at runtime and shared by every screen that has a preview.
+var splitLayout = false
+
+// splitMinWidth is the narrowest terminal that still shows the preview pane;
+// anything narrower falls back to paging between the list and the content.
+const splitMinWidth = 100
+
+// splitView puts a screen's list pages on the left and a preview of the
+// highlighted item on the right. When the split is off or the terminal is
+// too narrow, the preview takes no space and only the pages are shown.
+type splitView struct {
+	*tview.Flex
+	pages   *tview.Pages
+	divider *tview.Box
+	preview *tview.TextView
+	active  bool // whether the preview was visible on the last draw
+}
+
+func newSplitView(pages *tview.Pages) *splitView {
+	sv := &splitView{
+		Flex:    tview.NewFlex(),
+		pages:   pages,
+		preview: tview.NewTextView().SetScrollable(true),
+	}
+	sv.divider = tview.NewBox().SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		style := tcell.StyleDefault.Background(tview.Styles.PrimitiveBackgroundColor).Foreground(tview.Styles.BorderColor)
+		for row := y; row < y+height; row++ {
+			screen.SetContent(x, row, tview.Borders.Vertical, nil, style)
+		}
+		return x, y, width, height
+	})
+	sv.AddItem(pages, 0, 1, true).
+		AddItem(sv.divider, 0, 0, false).
+		AddItem(sv.preview, 0, 0, false)
+	return sv
+}
+
+func (sv *splitView) Draw(screen tcell.Screen) {
+	_, _, width, _ := sv.GetRect()
+	sv.active = splitLayout && width >= splitMinWidth
+	if sv.active {
+		sv.ResizeItem(sv.divider, 1, 0)
+		sv.ResizeItem(sv.preview, 0, 2)
+	} else {
+		sv.ResizeItem(sv.divider, 0, 0)
+		sv.ResizeItem(sv.preview, 0, 0)
+	}
+	sv.Flex.Draw(screen)
+}
+
+// show replaces the preview text and scrolls back to the top.
+func (sv *splitView) show(text string) {
+	sv.preview.SetText(text)
+	sv.preview.ScrollToBeginning()
+}
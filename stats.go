@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- StatsScreen ---
+
+type StatsScreen struct {
+	Root    *tview.Grid
+	days    *tview.InputField
+	dormant *tview.InputField
+	content *statsView
+	data    *TrailData
+	app     *tview.Application
+	window  int
+	idle    int
+}
+
+// countRow is one bar in a stats chart.
+type countRow struct {
+	label string
+	count int
+	last  time.Time
+}
+
+// windowStats summarises activity in the days-long window ending today.
+type windowStats struct {
+	from, to      time.Time
+	days          int
+	entries       int
+	activeDays    int
+	longestStreak int
+	projects      []countRow
+	tasks         []countRow
+	dormant       []countRow // projects with no entry in the dormant threshold
+	dormantDays   int
+	today         time.Time
+}
+
+func computeStats(data *TrailData, days, dormantDays int, today time.Time) windowStats {
+	st := windowStats{
+		from:        today.AddDate(0, 0, -(days - 1)),
+		to:          today,
+		days:        days,
+		dormantDays: dormantDays,
+		today:       today,
+	}
+	activeDates := make(map[time.Time]struct{})
+	dormantCutoff := today.AddDate(0, 0, -(dormantDays - 1))
+
+	for projectName, project := range data.Projects {
+		projectRow := countRow{label: projectName}
+		var projectLast time.Time
+		for taskName, entries := range project.Tasks {
+			taskRow := countRow{label: projectName + "/" + taskName}
+			for _, entry := range entries {
+				if entry.Date.After(projectLast) && !entry.Date.After(today) {
+					projectLast = entry.Date
+				}
+				if entry.Date.Before(st.from) || entry.Date.After(today) {
+					continue
+				}
+				st.entries++
+				projectRow.count++
+				taskRow.count++
+				taskRow.last = later(taskRow.last, entry.Date)
+				projectRow.last = later(projectRow.last, entry.Date)
+				activeDates[entry.Date] = struct{}{}
+			}
+			if taskRow.count > 0 {
+				st.tasks = append(st.tasks, taskRow)
+			}
+		}
+		if projectRow.count > 0 {
+			st.projects = append(st.projects, projectRow)
+		}
+		if !projectLast.IsZero() && projectLast.Before(dormantCutoff) {
+			st.dormant = append(st.dormant, countRow{label: projectName, last: projectLast})
+		}
+	}
+
+	st.activeDays = len(activeDates)
+	streak := 0
+	for d := st.from; !d.After(today); d = d.AddDate(0, 0, 1) {
+		if _, ok := activeDates[d]; ok {
+			streak++
+			st.longestStreak = max(st.longestStreak, streak)
+		} else {
+			streak = 0
+		}
+	}
+
+	byCount := func(a, b countRow) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		if c := b.last.Compare(a.last); c != 0 {
+			return c
+		}
+		return strings.Compare(a.label, b.label)
+	}
+	slices.SortFunc(st.projects, byCount)
+	slices.SortFunc(st.tasks, byCount)
+	slices.SortFunc(st.dormant, func(a, b countRow) int {
+		if c := b.last.Compare(a.last); c != 0 {
+			return c
+		}
+		return strings.Compare(a.label, b.label)
+	})
+	return st
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// renderStats lays the summary out for a content area width columns wide.
+func renderStats(st windowStats, width int) string {
+	accent := colorTag(theme.Accent)
+	const reset = "[-]"
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%sWindow%s  %s – %s (%d %s)\n\n", accent, reset,
+		st.from.Format(longDate), st.to.Format(longDate), st.days, plural(st.days, "day", "days"))
+
+	fmt.Fprintf(&sb, "Active days      %d / %d\n", st.activeDays, st.days)
+	fmt.Fprintf(&sb, "Longest streak   %d %s\n", st.longestStreak, plural(st.longestStreak, "day", "days"))
+	perDay := float64(st.entries) / float64(st.days)
+	perActive := 0.0
+	if st.activeDays > 0 {
+		perActive = float64(st.entries) / float64(st.activeDays)
+	}
+	fmt.Fprintf(&sb, "Entries          %d (%.1f per day, %.1f per active day)\n", st.entries, perDay, perActive)
+	if len(st.tasks) > 0 {
+		top := st.tasks[0]
+		project, task, _ := strings.Cut(top.label, "/")
+		fmt.Fprintf(&sb, "Most touched     %s@%s%s/%s+%s%s (%d %s)\n", colorTag(theme.Project), project, reset, colorTag(theme.Task), task, reset,
+			top.count, plural(top.count, "entry", "entries"))
+	}
+
+	fmt.Fprintf(&sb, "\n%sEntries per project%s\n", accent, reset)
+	writeBars(&sb, st.projects, width)
+	fmt.Fprintf(&sb, "\n%sEntries per task%s\n", accent, reset)
+	writeBars(&sb, st.tasks, width)
+
+	fmt.Fprintf(&sb, "\n%sDormant projects%s (no entry in %d %s)\n", accent, reset, st.dormantDays, plural(st.dormantDays, "day", "days"))
+	if len(st.dormant) == 0 {
+		sb.WriteString("  (none)\n")
+	}
+	labelWidth := 0
+	for _, row := range st.dormant {
+		labelWidth = max(labelWidth, len([]rune(row.label)))
+	}
+	for _, row := range st.dormant {
+		ago := int(st.today.Sub(row.last).Hours() / 24)
+		fmt.Fprintf(&sb, "  %-*s  last entry %s (%d days ago)\n", labelWidth, row.label, row.last.Format(longDate), ago)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// writeBars draws one horizontal bar per row, scaled so the largest count
+// fills whatever width is left after the labels.
+func writeBars(sb *strings.Builder, rows []countRow, width int) {
+	if len(rows) == 0 {
+		sb.WriteString("  (no entries)\n")
+		return
+	}
+	labelWidth, maxCount := 0, 0
+	for _, row := range rows {
+		labelWidth = max(labelWidth, len([]rune(row.label)))
+		maxCount = max(maxCount, row.count)
+	}
+	countWidth := len(strconv.Itoa(maxCount))
+	// "  " + label + "  " + bar + " " + count
+	barWidth := max(1, width-labelWidth-countWidth-5)
+	for _, row := range rows {
+		n := max(1, row.count*barWidth/maxCount)
+		fmt.Fprintf(sb, "  %-*s  %s%s[-] %d\n", labelWidth, row.label, colorTag(theme.Accent), strings.Repeat("█", n), row.count)
+	}
+}
+
+// statsView re-renders its text whenever the available width changes, so
+// the bars always fit the terminal.
+type statsView struct {
+	*tview.TextView
+	render    func(width int) string
+	lastWidth int
+}
+
+func (sv *statsView) Draw(screen tcell.Screen) {
+	_, _, width, _ := sv.GetInnerRect()
+	if width != sv.lastWidth {
+		sv.lastWidth = width
+		sv.SetText(sv.render(width))
+	}
+	sv.TextView.Draw(screen)
+}
+
+func (sv *statsView) refresh() {
+	sv.SetText(sv.render(sv.lastWidth))
+}
+
+func newStatsScreen(data *TrailData, app *tview.Application) *StatsScreen {
+	ss := &StatsScreen{data: data, app: app, window: 7, idle: 14}
+
+	ss.content = &statsView{
+		TextView: tview.NewTextView().SetScrollable(true).SetWrap(false).SetDynamicColors(true),
+		render: func(width int) string {
+			if ss.window <= 0 || ss.idle <= 0 {
+				return ""
+			}
+			today := currentDay()
+			return renderStats(computeStats(data, ss.window, ss.idle, today), width)
+		},
+	}
+
+	ss.days = tview.NewInputField().
+		SetLabel("Last N days: ").
+		SetText(strconv.Itoa(ss.window)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetChangedFunc(func(text string) {
+			ss.window, _ = strconv.Atoi(text)
+			ss.content.refresh()
+		})
+	ss.days.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			app.SetFocus(ss.dormant)
+		}
+	})
+
+	ss.dormant = tview.NewInputField().
+		SetLabel("Dormant after N days: ").
+		SetText(strconv.Itoa(ss.idle)).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetChangedFunc(func(text string) {
+			ss.idle, _ = strconv.Atoi(text)
+			ss.content.refresh()
+		})
+	ss.dormant.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			app.SetFocus(ss.content)
+		}
+	})
+
+	inputs := tview.NewFlex().
+		AddItem(ss.days, 0, 1, false).
+		AddItem(ss.dormant, 0, 1, false)
+
+	ss.Root = tview.NewGrid().SetRows(1, 0).SetColumns(0).SetBorders(true)
+	ss.Root.AddItem(inputs, 0, 0, 1, 1, 0, 0, false)
+	ss.Root.AddItem(ss.content, 1, 0, 1, 1, 0, 0, true)
+
+	return ss
+}
+
+// refresh redraws the summary from the notes.
+func (ss *StatsScreen) refresh() {
+	ss.content.refresh()
+}
+
+func (ss *StatsScreen) restyle() {
+	restyle(ss.Root, ss.days, ss.dormant, ss.content)
+}
+
+func (ss *StatsScreen) handleEsc() {
+	focus := ss.app.GetFocus()
+	if focus == ss.days || focus == ss.dormant {
+		ss.app.SetFocus(ss.content)
+	}
+}
+
+func (ss *StatsScreen) focusFilter() {
+	ss.app.SetFocus(ss.days)
+}
+
+// yankText is the summary as shown, in a code block so the bars line up.
+func (ss *StatsScreen) yankText() (text, what string) {
+	if ss.window <= 0 || ss.idle <= 0 {
+		return "", ""
+	}
+	return "```\n" + stripColorTags(ss.content.render(ss.content.lastWidth)) + "\n```\n", "stats"
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tabBar draws one tab per screen along the top, highlighting the current
+// one. Clicking a tab switches to that screen.
+type tabBar struct {
+	*tview.Box
+	current  *string
+	selected func(name string)
+	hint     string    // shown at the right, e.g. "? help"
+	spans    []tabSpan // where each tab was drawn, for mouse hits
+}
+
+type tabSpan struct {
+	name     string
+	from, to int // screen columns, to exclusive
+}
+
+func newTabBar(current *string, selected func(name string)) *tabBar {
+	return &tabBar{Box: tview.NewBox(), current: current, selected: selected}
+}
+
+func (tb *tabBar) Draw(screen tcell.Screen) {
+	tb.DrawForSubclass(screen, tb)
+	x, y, width, _ := tb.GetInnerRect()
+
+	normal := tcell.StyleDefault.Background(theme.Background).Foreground(theme.Dim)
+	active := tcell.StyleDefault.Background(theme.Accent).Foreground(theme.Background).Bold(true)
+
+	tb.spans = tb.spans[:0]
+	col := x
+	for _, name := range screenNames {
+		label := " " + name + " "
+		if col+len(label) > x+width {
+			break
+		}
+		style := normal
+		if name == *tb.current {
+			style = active
+		}
+		for i, r := range label {
+			screen.SetContent(col+i, y, r, nil, style)
+		}
+		tb.spans = append(tb.spans, tabSpan{name: name, from: col, to: col + len(label)})
+		col += len(label) + 1
+	}
+
+	if hintWidth := len([]rune(tb.hint)) + 1; tb.hint != "" && col+hintWidth <= x+width {
+		tview.Print(screen, tview.Escape(tb.hint)+" ", x, y, width, tview.AlignRight, theme.Dim)
+	}
+}
+
+func (tb *tabBar) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return tb.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		x, y := event.Position()
+		if action != tview.MouseLeftClick || !tb.InRect(x, y) {
+			return false, nil
+		}
+		for _, span := range tb.spans {
+			if x >= span.from && x < span.to {
+				if tb.selected != nil {
+					tb.selected(span.name)
+				}
+				break
+			}
+		}
+		return true, nil
+	})
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme holds every colour trail draws with, named by what it is used for
+// rather than where.
+type Theme struct {
+	Name       string
+	Background tcell.Color // screen background
+	Surface    tcell.Color // input fields
+	Overlay    tcell.Color // dropdowns and popups
+	Border     tcell.Color // grid borders, dividers and empty cells
+	Text       tcell.Color // body text
+	Dim        tcell.Color // hints, axis labels and other secondary text
+	Accent     tcell.Color // titles, input labels and box outlines
+	Project    tcell.Color // @project names
+	Task       tcell.Color // +task names
+	Date       tcell.Color // date headings
+	Todo       tcell.Color // entries that are still to do
+	Highlight  tcell.Color // fuzzy matches and cursors
+}
+
+// theme is the active theme. Use applyTheme to change it so tview's own
+// styles follow.
+var theme = tokyoNight
+
+var tokyoNight = Theme{
+	Name:       "tokyo-night",
+	Background: tcell.NewHexColor(0x1a1b26),
+	Surface:    tcell.NewHexColor(0x16161e),
+	Overlay:    tcell.NewHexColor(0x414868),
+	Border:     tcell.NewHexColor(0x414868),
+	Text:       tcell.NewHexColor(0xa9b1d6),
+	Dim:        tcell.NewHexColor(0x787c99),
+	Accent:     tcell.NewHexColor(0x7aa2f7),
+	Project:    tcell.NewHexColor(0xe0af68),
+	Task:       tcell.NewHexColor(0xe0af68),
+	Date:       tcell.NewHexColor(0xa9b1d6),
+	Todo:       tcell.NewHexColor(0xf7768e),
+	Highlight:  tcell.NewHexColor(0xff9e64),
+}
+
+var lightTheme = Theme{
+	Name:       "light",
+	Background: tcell.NewHexColor(0xe1e2e7),
+	Surface:    tcell.NewHexColor(0xd0d5e3),
+	Overlay:    tcell.NewHexColor(0xc4c8da),
+	Border:     tcell.NewHexColor(0xa8aecb),
+	Text:       tcell.NewHexColor(0x3760bf),
+	Dim:        tcell.NewHexColor(0x6172b0),
+	Accent:     tcell.NewHexColor(0x2e7de9),
+	Project:    tcell.NewHexColor(0x8c6c3e),
+	Task:       tcell.NewHexColor(0x9854f1),
+	Date:       tcell.NewHexColor(0x3760bf),
+	Todo:       tcell.NewHexColor(0xf52a65),
+	Highlight:  tcell.NewHexColor(0xb15c00),
+}
+
+var highContrastTheme = Theme{
+	Name:       "high-contrast",
+	Background: tcell.NewHexColor(0x000000),
+	Surface:    tcell.NewHexColor(0x1c1c1c),
+	Overlay:    tcell.NewHexColor(0x444444),
+	Border:     tcell.NewHexColor(0xffffff),
+	Text:       tcell.NewHexColor(0xffffff),
+	Dim:        tcell.NewHexColor(0xc0c0c0),
+	Accent:     tcell.NewHexColor(0x00ffff),
+	Project:    tcell.NewHexColor(0xffff00),
+	Task:       tcell.NewHexColor(0x00ff00),
+	Date:       tcell.NewHexColor(0xffffff),
+	Todo:       tcell.NewHexColor(0xff5f5f),
+	Highlight:  tcell.NewHexColor(0xff00ff),
+}
+
+// basicTheme only uses the 16 ANSI palette colours, so it follows the
+// terminal's own palette and works where true colour is unavailable.
+var basicTheme = Theme{
+	Name:       "basic",
+	Background: tcell.ColorBlack,
+	Surface:    tcell.ColorNavy,
+	Overlay:    tcell.ColorGray,
+	Border:     tcell.ColorGray,
+	Text:       tcell.ColorSilver,
+	Dim:        tcell.ColorGray,
+	Accent:     tcell.ColorBlue,
+	Project:    tcell.ColorYellow,
+	Task:       tcell.ColorLime,
+	Date:       tcell.ColorSilver,
+	Todo:       tcell.ColorRed,
+	Highlight:  tcell.ColorFuchsia,
+}
+
+// builtinThemes are the themes the theme key cycles through, in order.
+var builtinThemes = []Theme{tokyoNight, lightTheme, highContrastTheme, basicTheme}
+
+// themeColors maps config.toml colour keys to the Theme field they set.
+var themeColors = map[string]func(*Theme) *tcell.Color{
+	"background": func(t *Theme) *tcell.Color { return &t.Background },
+	"surface":    func(t *Theme) *tcell.Color { return &t.Surface },
+	"overlay":    func(t *Theme) *tcell.Color { return &t.Overlay },
+	"border":     func(t *Theme) *tcell.Color { return &t.Border },
+	"text":       func(t *Theme) *tcell.Color { return &t.Text },
+	"dim":        func(t *Theme) *tcell.Color { return &t.Dim },
+	"accent":     func(t *Theme) *tcell.Color { return &t.Accent },
+	"project":    func(t *Theme) *tcell.Color { return &t.Project },
+	"task":       func(t *Theme) *tcell.Color { return &t.Task },
+	"date":       func(t *Theme) *tcell.Color { return &t.Date },
+	"todo":       func(t *Theme) *tcell.Color { return &t.Todo },
+	"highlight":  func(t *Theme) *tcell.Color { return &t.Highlight },
+}
+
+// tviewTheme maps the semantic colours onto tview's built-in styles.
+func (t Theme) tviewTheme() tview.Theme {
+	return tview.Theme{
+		PrimitiveBackgroundColor:    t.Background,
+		ContrastBackgroundColor:     t.Surface,
+		MoreContrastBackgroundColor: t.Overlay,
+		BorderColor:                 t.Border,
+		TitleColor:                  t.Accent,
+		GraphicsColor:               t.Border,
+		PrimaryTextColor:            t.Text,
+		SecondaryTextColor:          t.Accent,
+		TertiaryTextColor:           t.Dim,
+		InverseTextColor:            t.Background,
+		ContrastSecondaryTextColor:  t.Text,
+	}
+}
+
+// applyTheme makes t the active theme. tview primitives copy their colours
+// when they are created, so ones that already exist need restyling.
+func applyTheme(t Theme) {
+	theme = t
+	tview.Styles = t.tviewTheme()
+}
+
+// restyle gives primitives created under an earlier theme the colours they
+// would get if created now. What flexes and pages hold is restyled with
+// them; grids don't list their items, so pass those as well.
+func restyle(primitives ...tview.Primitive) {
+	s := tview.Styles
+	for _, p := range primitives {
+		switch p := p.(type) {
+		case *tview.List:
+			p.SetMainTextStyle(tcell.StyleDefault.Foreground(s.PrimaryTextColor).Background(s.PrimitiveBackgroundColor)).
+				SetSecondaryTextStyle(tcell.StyleDefault.Foreground(s.TertiaryTextColor).Background(s.PrimitiveBackgroundColor)).
+				SetShortcutStyle(tcell.StyleDefault.Foreground(s.SecondaryTextColor).Background(s.PrimitiveBackgroundColor)).
+				SetSelectedStyle(tcell.StyleDefault.Foreground(s.PrimitiveBackgroundColor).Background(s.PrimaryTextColor))
+		case *tview.TextView:
+			p.SetTextStyle(tcell.StyleDefault.Foreground(s.PrimaryTextColor).Background(s.PrimitiveBackgroundColor))
+		case *tview.InputField:
+			p.SetPlaceholderStyle(tcell.StyleDefault.Foreground(s.ContrastSecondaryTextColor).Background(s.ContrastBackgroundColor))
+			// The form attributes are the only way to reach the background
+			// behind the label.
+			p.SetFormAttributes(0, s.SecondaryTextColor, s.PrimitiveBackgroundColor, s.PrimaryTextColor, s.ContrastBackgroundColor)
+		case *tview.Grid:
+			p.SetBordersColor(s.GraphicsColor)
+		case *tview.Flex:
+			for i := range p.GetItemCount() {
+				restyle(p.GetItem(i))
+			}
+		case *tview.Pages:
+			for _, name := range p.GetPageNames(false) {
+				restyle(p.GetPage(name))
+			}
+		case *splitView:
+			restyle(p.Flex)
+		case *statsView:
+			restyle(p.TextView)
+		}
+		if box, ok := p.(interface {
+			SetBackgroundColor(tcell.Color) *tview.Box
+		}); ok {
+			box.SetBackgroundColor(s.PrimitiveBackgroundColor).
+				SetBorderColor(s.BorderColor).
+				SetTitleColor(s.TitleColor)
+		}
+	}
+}
+
+// colorTag returns a tview colour tag for c, using the colour's name when it
+// has one so palette colours stay palette colours.
+func colorTag(c tcell.Color) string {
+	return "[" + c.String() + "]"
+}
+
+// isTodo reports whether an entry is an open to-do item, written either as
+// "TODO" or as an unchecked "[ ]" box after the bullet.
+func isTodo(content string) bool {
+	content = strings.TrimLeft(content, "*- \t")
+	for _, prefix := range []string{"TODO", "[ ]", `\[ \]`} {
+		if strings.HasPrefix(content, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- TimelineScreen ---
+
+type TimelineScreen struct {
+	Root  *tview.Grid
+	chart *timelineView
+	help  *tview.TextView
+	data  *TrailData
+	app   *tview.Application
+}
+
+func newTimelineScreen(data *TrailData, app *tview.Application) *TimelineScreen {
+	tls := &TimelineScreen{data: data, app: app}
+
+	tls.chart = newTimelineView(data)
+	tls.help = defaultText("j/k select · Enter expand · h/l scroll · H/L first/last · t today").
+		SetTextColor(tview.Styles.TertiaryTextColor)
+
+	tls.Root = tview.NewGrid().SetRows(0, 1).SetColumns(0).SetBorders(true)
+	tls.Root.AddItem(tls.chart, 0, 0, 1, 1, 0, 0, true)
+	tls.Root.AddItem(tls.help, 1, 0, 1, 1, 0, 0, false)
+	return tls
+}
+
+// refresh redraws the bars from the notes, keeping the cursor, the expanded
+// projects and the scroll position.
+func (tls *TimelineScreen) refresh() {
+	tls.chart.setSpans(buildTimeline(tls.data))
+}
+
+func (tls *TimelineScreen) restyle() {
+	restyle(tls.Root, tls.chart, tls.help)
+	tls.help.SetTextColor(tview.Styles.TertiaryTextColor)
+}
+
+// yankText is the history of the project or task under the cursor.
+func (tls *TimelineScreen) yankText() (text, what string) {
+	rows := tls.chart.rows()
+	if len(rows) == 0 {
+		return "", ""
+	}
+	row := rows[tls.chart.cursor]
+	project := tls.data.Projects[row.project]
+	if row.task == "" {
+		return markdownProject(project), "@" + row.project
+	}
+	return markdownTask(row.project, row.task, project.Tasks[row.task]), "@" + row.project + " +" + row.task
+}
+
+// timelineSpan is one bar on the timeline: a project, or one of its tasks
+// when task is set.
+type timelineSpan struct {
+	project string
+	task    string
+	first   time.Time
+	last    time.Time
+	active  map[time.Time]bool
+	tasks   []*timelineSpan
+}
+
+func newTimelineSpan(project, task string) *timelineSpan {
+	return &timelineSpan{project: project, task: task, active: make(map[time.Time]bool)}
+}
+
+func (span *timelineSpan) add(date time.Time) {
+	if span.first.IsZero() || date.Before(span.first) {
+		span.first = date
+	}
+	if date.After(span.last) {
+		span.last = date
+	}
+	span.active[date] = true
+}
+
+// buildTimeline collects one span per project, each with its task spans,
+// ordered by when the work started.
+func buildTimeline(data *TrailData) []*timelineSpan {
+	byStart := func(a, b *timelineSpan) int {
+		if c := a.first.Compare(b.first); c != 0 {
+			return c
+		}
+		return strings.Compare(a.project+"/"+a.task, b.project+"/"+b.task)
+	}
+
+	var spans []*timelineSpan
+	for projectName, project := range data.Projects {
+		projectSpan := newTimelineSpan(projectName, "")
+		for taskName, entries := range project.Tasks {
+			if len(entries) == 0 {
+				continue
+			}
+			taskSpan := newTimelineSpan(projectName, taskName)
+			for _, entry := range entries {
+				taskSpan.add(entry.Date)
+				projectSpan.add(entry.Date)
+			}
+			projectSpan.tasks = append(projectSpan.tasks, taskSpan)
+		}
+		if len(projectSpan.tasks) == 0 {
+			continue
+		}
+		slices.SortFunc(projectSpan.tasks, byStart)
+		spans = append(spans, projectSpan)
+	}
+	slices.SortFunc(spans, byStart)
+	return spans
+}
+
+// timelineView draws each project as a horizontal bar from its first to its
+// last entry, with a tick on every day that has activity. Projects can be
+// expanded to show a bar per task, and the time axis scrolls sideways.
+type timelineView struct {
+	*tview.Box
+	spans    []*timelineSpan
+	expanded map[string]bool
+	cursor   int       // index into rows()
+	offset   int       // first visible row
+	end      time.Time // rightmost visible day
+	earliest time.Time
+	latest   time.Time
+	today    time.Time
+	columns  int // days visible on the last draw
+}
+
+func newTimelineView(data *TrailData) *timelineView {
+	tv := &timelineView{
+		Box:      tview.NewBox(),
+		expanded: make(map[string]bool),
+		today:    currentDay(),
+	}
+	tv.setSpans(buildTimeline(data))
+	// Start with the most recent activity at the right edge.
+	tv.end = tv.earliest
+	for _, span := range tv.spans {
+		tv.end = later(tv.end, span.last)
+	}
+	return tv
+}
+
+// setSpans replaces the bars, keeping the cursor on the same row number.
+func (tv *timelineView) setSpans(spans []*timelineSpan) {
+	tv.spans = spans
+	tv.earliest = time.Time{}
+	tv.latest = tv.today
+	for _, span := range tv.spans {
+		if tv.earliest.IsZero() || span.first.Before(tv.earliest) {
+			tv.earliest = span.first
+		}
+		tv.latest = later(tv.latest, span.last)
+	}
+	if tv.earliest.IsZero() {
+		tv.earliest = tv.today
+	}
+	tv.cursor = max(0, min(tv.cursor, len(tv.rows())-1))
+}
+
+// rows flattens the spans into what is currently on screen, expanding
+// projects the user has opened.
+func (tv *timelineView) rows() []*timelineSpan {
+	var rows []*timelineSpan
+	for _, span := range tv.spans {
+		rows = append(rows, span)
+		if tv.expanded[span.project] {
+			rows = append(rows, span.tasks...)
+		}
+	}
+	return rows
+}
+
+func (span *timelineSpan) label(expanded bool) string {
+	if span.task != "" {
+		return "    +" + span.task
+	}
+	if expanded {
+		return "▾ @" + span.project
+	}
+	return "▸ @" + span.project
+}
+
+func (tv *timelineView) Draw(screen tcell.Screen) {
+	tv.DrawForSubclass(screen, tv)
+	x, y, width, height := tv.GetInnerRect()
+	rows := tv.rows()
+	if height < 2 || len(rows) == 0 {
+		tview.Print(screen, "(no entries)", x, y, width, tview.AlignCenter, tview.Styles.TertiaryTextColor)
+		return
+	}
+
+	labelWidth := 0
+	for _, row := range rows {
+		labelWidth = max(labelWidth, len([]rune(row.label(true))))
+	}
+	labelWidth = min(labelWidth, width/3)
+	chartX := x + labelWidth + 1
+	chartWidth := width - labelWidth - 1
+	if chartWidth <= 0 {
+		return
+	}
+	tv.columns = chartWidth
+	start := tv.end.AddDate(0, 0, -(chartWidth - 1))
+
+	bg := tview.Styles.PrimitiveBackgroundColor
+	dim := tview.Styles.TertiaryTextColor
+	barStyle := tcell.StyleDefault.Background(bg).Foreground(theme.Border)
+	tickStyle := tcell.StyleDefault.Background(bg).Foreground(theme.Accent)
+	todayStyle := tcell.StyleDefault.Background(bg).Foreground(dim)
+
+	// Axis: a month label wherever a month starts, with the year in January
+	// and on the leftmost column.
+	nextFree := chartX
+	for col := 0; col < chartWidth; col++ {
+		d := start.AddDate(0, 0, col)
+		if col != 0 && d.Day() != 1 {
+			continue
+		}
+		label := d.Format("Jan")
+		if col == 0 || d.Month() == time.January {
+			label = d.Format("Jan 2006")
+		}
+		if cx := chartX + col; cx >= nextFree {
+			tview.Print(screen, label, cx, y, x+width-cx, tview.AlignLeft, dim)
+			nextFree = cx + len(label) + 1
+		}
+	}
+
+	visible := height - 1
+	tv.cursor = max(0, min(tv.cursor, len(rows)-1))
+	if tv.cursor < tv.offset {
+		tv.offset = tv.cursor
+	}
+	if tv.cursor >= tv.offset+visible {
+		tv.offset = tv.cursor - visible + 1
+	}
+
+	for i := 0; i < visible && tv.offset+i < len(rows); i++ {
+		row := rows[tv.offset+i]
+		ry := y + 1 + i
+
+		labelColor := theme.Task
+		if row.task == "" {
+			labelColor = theme.Project
+		}
+		tview.Print(screen, row.label(tv.expanded[row.project]), x, ry, labelWidth, tview.AlignLeft, labelColor)
+		if tv.offset+i == tv.cursor && tv.HasFocus() {
+			for cx := x; cx < x+labelWidth; cx++ {
+				mainc, combc, style, _ := screen.GetContent(cx, ry)
+				screen.SetContent(cx, ry, mainc, combc, style.Reverse(true))
+			}
+		}
+
+		for col := 0; col < chartWidth; col++ {
+			d := start.AddDate(0, 0, col)
+			switch {
+			case row.active[d]:
+				screen.SetContent(chartX+col, ry, '█', nil, tickStyle)
+			case !d.Before(row.first) && !d.After(row.last):
+				screen.SetContent(chartX+col, ry, '━', nil, barStyle)
+			case d.Equal(tv.today):
+				screen.SetContent(chartX+col, ry, '┊', nil, todayStyle)
+			}
+		}
+	}
+}
+
+// scroll moves the right edge of the time axis by days, staying within the
+// range that has any activity (or today).
+func (tv *timelineView) scroll(days int) {
+	tv.end = tv.end.AddDate(0, 0, days)
+	if tv.end.After(tv.latest) {
+		tv.end = tv.latest
+	}
+	if tv.end.Before(tv.earliest) {
+		tv.end = tv.earliest
+	}
+}
+
+// toggle expands or collapses the project under the cursor. On a task row
+// it collapses the parent project and moves the cursor onto it.
+func (tv *timelineView) toggle() {
+	rows := tv.rows()
+	if len(rows) == 0 {
+		return
+	}
+	row := rows[tv.cursor]
+	if row.task != "" {
+		for rows[tv.cursor].task != "" {
+			tv.cursor--
+		}
+		tv.expanded[row.project] = false
+		return
+	}
+	tv.expanded[row.project] = !tv.expanded[row.project]
+}
+
+// showFirst scrolls so the earliest activity is at the left edge.
+func (tv *timelineView) showFirst() {
+	tv.end = tv.earliest
+	tv.scroll(tv.columns - 1)
+}
+
+// showLast scrolls so the latest activity is at the right edge.
+func (tv *timelineView) showLast() {
+	tv.end = tv.latest
+}
+
+func (tv *timelineView) showToday() {
+	tv.end = tv.today
+}
+
+// MouseHandler selects a row on click, expands or collapses it on a double
+// click, and moves between rows with the scroll wheel.
+func (tv *timelineView) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return tv.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		x, y := event.Position()
+		if !tv.InRect(x, y) {
+			return false, nil
+		}
+		_, rectY, _, _ := tv.GetInnerRect()
+		row := tv.offset + y - rectY - 1
+		onRow := y > rectY && row < len(tv.rows())
+		switch action {
+		case tview.MouseLeftDown:
+			setFocus(tv)
+		case tview.MouseLeftClick:
+			if onRow {
+				tv.cursor = row
+			}
+		case tview.MouseLeftDoubleClick:
+			if onRow {
+				tv.cursor = row
+				tv.toggle()
+			}
+		case tview.MouseScrollUp:
+			tv.cursor = max(0, tv.cursor-1)
+		case tview.MouseScrollDown:
+			tv.cursor = min(len(tv.rows())-1, tv.cursor+1)
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+func (tv *timelineView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return tv.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyUp:
+			tv.cursor--
+		case tcell.KeyDown:
+			tv.cursor++
+		case tcell.KeyLeft:
+			tv.scroll(-7)
+		case tcell.KeyRight:
+			tv.scroll(7)
+		case tcell.KeyEnter:
+			tv.toggle()
+		case tcell.KeyHome:
+			tv.cursor = 0
+		case tcell.KeyEnd:
+			tv.cursor = len(tv.rows()) - 1
+		case tcell.KeyPgUp, tcell.KeyPgDn:
+			_, _, _, height := tv.GetInnerRect()
+			page := max(1, height-1)
+			if event.Key() == tcell.KeyPgUp {
+				page = -page
+			}
+			tv.cursor += page
+		}
+		tv.cursor = max(0, min(tv.cursor, len(tv.rows())-1))
+	})
+}
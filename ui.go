@@ -0,0 +1,587 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- UI ---
+
+// UI owns the screens and routes the global keys between them.
+type UI struct {
+	app     *tview.Application
+	data    *TrailData // what the screens show: all, or source's and author's notes
+	all     TrailData  // everything loaded
+	source  int        // index into cfg.sources shown alone, or -1 for all
+	author  string     // only author's entries are shown, unless empty
+	cfg     *Config
+	root    *tview.Pages // the tabs and screens, with the help overlay on top
+	pages   *tview.Pages // one page per screen
+	tabs    *tabBar
+	status  *tview.TextView
+	current string
+	themes  []Theme
+	screen  tcell.Screen // for writing to the terminal directly
+	flashes int          // status messages shown, so only the last one is cleared
+
+	helpReturn tview.Primitive // focus to restore when the help closes
+
+	pinned   *PinnedScreen
+	projects *ProjectsScreen
+	tasks    *TasksScreen
+	days     *DaysScreen
+	week     *WeekScreen
+	calendar *CalendarScreen
+	recent   *RecentScreen
+	timeline *TimelineScreen
+	stats    *StatsScreen
+	authors  *AuthorsScreen
+}
+
+func newUI(app *tview.Application, data *TrailData, cfg *Config) *UI {
+	u := &UI{app: app, data: data, all: *data, source: -1, cfg: cfg, current: cfg.DefaultScreen}
+
+	// Cycle through the built-in themes, with the configured one (including
+	// any colour overrides) standing in for its built-in namesake.
+	u.themes = slices.Clone(builtinThemes)
+	for i, t := range u.themes {
+		if t.Name == cfg.theme.Name {
+			u.themes[i] = cfg.theme
+		}
+	}
+
+	u.build()
+	app.SetInputCapture(u.handleKey)
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		u.screen = screen
+		return false
+	})
+	return u
+}
+
+// build creates every screen and lays them out under the tabs.
+func (u *UI) build() {
+	u.pages = tview.NewPages()
+
+	u.pinned = newPinnedScreen(u.data, u.app)
+	u.projects = newProjectsScreen(u.data, u.app)
+	u.tasks = newTasksScreen(u.data, u.app)
+	u.days = newDaysScreen(u.data, u.app)
+	u.week = newWeekScreen(u.data, u.app)
+	u.calendar = newCalendarScreen(u.data, u.app, func(date time.Time) {
+		u.showScreen("days")
+		u.days.showDetail(date)
+	})
+	u.recent = newRecentScreen(u.data, u.app, u.cfg.RecentDays, func(project, task string) {
+		if task == "" {
+			u.showScreen("projects")
+			u.projects.openProject(project)
+			return
+		}
+		u.showScreen("tasks")
+		u.tasks.openTask(project, task)
+	})
+	u.timeline = newTimelineScreen(u.data, u.app)
+	u.stats = newStatsScreen(u.data, u.app)
+	u.authors = newAuthorsScreen(u.data, u.app)
+
+	u.pages.AddPage("pinned", u.pinned.Root, true, false)
+	u.pages.AddPage("projects", u.projects.Root, true, false)
+	u.pages.AddPage("tasks", u.tasks.Root, true, false)
+	u.pages.AddPage("days", u.days.Root, true, false)
+	u.pages.AddPage("week", u.week.Root, true, false)
+	u.pages.AddPage("calendar", u.calendar.Root, true, false)
+	u.pages.AddPage("recent", u.recent.Root, true, false)
+	u.pages.AddPage("timeline", u.timeline.Root, true, false)
+	u.pages.AddPage("stats", u.stats.Root, true, false)
+	u.pages.AddPage("authors", u.authors.Root, true, false)
+	u.pages.SwitchToPage(u.current)
+
+	u.tabs = newTabBar(&u.current, func(name string) {
+		u.showScreen(name)
+		u.app.SetFocus(u.pages)
+	})
+	if keys := u.cfg.keys.describe("help"); keys != "" {
+		u.tabs.hint = keys + " help"
+	}
+	u.status = tview.NewTextView().SetDynamicColors(true).SetWrap(false).
+		SetText(u.statusText())
+	u.status.SetTextColor(theme.Dim)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.tabs, 1, 0, false).
+		AddItem(u.pages, 0, 1, true).
+		AddItem(u.status, 1, 0, false)
+
+	u.root = tview.NewPages().AddPage("screens", layout, true, true)
+	u.app.SetRoot(u.root, true).SetFocus(u.pages)
+}
+
+func (u *UI) showScreen(name string) {
+	u.current = name
+	u.pages.SwitchToPage(name)
+}
+
+// cycleTheme switches to the next theme, restyling the screens in place.
+// Text with colours written into it is rendered again.
+func (u *UI) cycleTheme() {
+	i := slices.IndexFunc(u.themes, func(t Theme) bool { return t.Name == theme.Name })
+	applyTheme(u.themes[(i+1)%len(u.themes)])
+	restyle(u.root)
+	u.status.SetTextColor(theme.Dim)
+	u.pinned.restyle()
+	u.projects.restyle()
+	u.tasks.restyle()
+	u.days.restyle()
+	u.week.restyle()
+	u.calendar.restyle()
+	u.recent.restyle()
+	u.timeline.restyle()
+	u.stats.restyle()
+	u.authors.restyle()
+	u.refresh()
+}
+
+// refresh shows the notes in u.data again on every screen, keeping what
+// each one has selected, filtered and open.
+func (u *UI) refresh() {
+	u.pinned.refresh()
+	u.projects.refresh()
+	u.tasks.refresh()
+	u.days.refresh()
+	u.week.refresh()
+	u.calendar.refresh()
+	u.recent.refresh()
+	u.timeline.refresh()
+	u.stats.refresh()
+	u.authors.refresh()
+	u.status.SetText(u.statusText())
+}
+
+// reload re-reads the notes from disk and refreshes the screens. If they
+// can't be read the notes already shown stay.
+func (u *UI) reload() {
+	all, err := loadTrailData(u.cfg)
+	if err != nil {
+		log.Println("reload:", err)
+		u.flash("reload failed: " + err.Error())
+		return
+	}
+	u.all = all
+	u.applyFilters()
+}
+
+// cycleSource steps from every source to each one on its own and back.
+func (u *UI) cycleSource() {
+	if len(u.cfg.sources) < 2 {
+		u.flash("only one notes source")
+		return
+	}
+	u.source++
+	if u.source == len(u.cfg.sources) {
+		u.source = -1
+	}
+	u.applyFilters()
+	if u.source < 0 {
+		u.flash("showing every source")
+	} else {
+		u.flash("showing " + u.cfg.sources[u.source].label + " only")
+	}
+}
+
+// cycleAuthor steps from everyone's entries to each author's on their own
+// and back.
+func (u *UI) cycleAuthor() {
+	authors := u.all.Authors()
+	if len(authors) == 0 {
+		u.flash("no authors; set author_dirs for notes kept per person")
+		return
+	}
+	i := slices.Index(authors, u.author) + 1
+	u.author = ""
+	if i < len(authors) {
+		u.author = authors[i]
+	}
+	u.applyFilters()
+	if u.author == "" {
+		u.flash("showing every author")
+	} else {
+		u.flash("showing " + u.author + " only")
+	}
+}
+
+// applyFilters narrows the notes down to the chosen source and author and
+// refreshes the screens, which only label entries with their source while
+// showing more than one.
+func (u *UI) applyFilters() {
+	data := u.all
+	if u.source >= 0 {
+		data = filterSource(data, u.cfg.sources[u.source])
+	}
+	if u.author != "" {
+		data = filterAuthor(data, u.author)
+	}
+	*u.data = data
+	setSourceLabels(u.shownSources())
+	u.refresh()
+}
+
+// shownSources is the sources the screens are showing.
+func (u *UI) shownSources() []noteSource {
+	if u.source < 0 {
+		return u.cfg.sources
+	}
+	return u.cfg.sources[u.source : u.source+1]
+}
+
+// editToday suspends trail to open today's note in $VISUAL or $EDITOR, then
+// reloads so the new entries show up.
+func (u *UI) editToday() {
+	if slices.ContainsFunc(u.cfg.sources, func(s noteSource) bool { return s.readOnly }) {
+		u.flash("notes from an archive can't be edited")
+		return
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	author := ""
+	if u.cfg.AuthorDirs {
+		if author = u.author; author == "" {
+			u.flash("press a to pick whose note to edit")
+			return
+		}
+	}
+	path := todayNotePath(u.cfg.NotesDirs, author)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		u.flash(err.Error())
+		return
+	}
+	args := append(strings.Fields(editor), path)
+	u.app.Suspend(func() {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			log.Println("editor:", err)
+		}
+	})
+	u.reload()
+}
+
+// todayNotePath finds the note whose file name carries today's date, or
+// names a new one in the first notes directory. With an author it looks in,
+// and names it in, their directory of each notes directory.
+func todayNotePath(dirs []string, author string) string {
+	date := currentDay().Format(filenameDateLayout)
+	for _, dir := range dirs {
+		dir = filepath.Join(dir, author)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".md" && strings.Contains(entry.Name(), date) {
+				return filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	return filepath.Join(dirs[0], author, date+".md")
+}
+
+// yank copies the current screen's selection to the clipboard as markdown.
+func (u *UI) yank() {
+	var text, what string
+	switch u.current {
+	case "pinned":
+		text, what = u.pinned.yankText()
+	case "projects":
+		text, what = u.projects.yankText()
+	case "tasks":
+		text, what = u.tasks.yankText()
+	case "days":
+		text, what = u.days.yankText()
+	case "week":
+		text, what = u.week.yankText()
+	case "calendar":
+		text, what = u.calendar.yankText()
+	case "recent":
+		text, what = u.recent.yankText()
+	case "timeline":
+		text, what = u.timeline.yankText()
+	case "stats":
+		text, what = u.stats.yankText()
+	case "authors":
+		text, what = u.authors.yankText()
+	}
+	if text == "" || u.screen == nil {
+		u.flash("nothing to copy")
+		return
+	}
+	copyToClipboard(u.screen, text)
+	u.flash("copied " + what)
+}
+
+// togglePin pins or unpins the task selected on the projects or tasks
+// screen.
+func (u *UI) togglePin() {
+	var project, task string
+	switch u.current {
+	case "projects":
+		project, task = u.projects.selectedTask()
+	case "tasks":
+		project, task = u.tasks.selectedTask()
+	}
+	if task == "" {
+		u.flash("select a task to pin")
+		return
+	}
+	pinned, err := pins.toggle(project, task)
+	u.projects.refresh()
+	u.tasks.refresh()
+	u.pinned.refresh()
+	switch {
+	case err != nil:
+		log.Println("pins:", err)
+		u.flash("could not save pins: " + err.Error())
+	case pinned:
+		u.flash("pinned @" + project + " +" + task)
+	default:
+		u.flash("unpinned @" + project + " +" + task)
+	}
+}
+
+// --- Status line ---
+
+// flash shows a message in the status line for a few seconds.
+func (u *UI) flash(message string) {
+	u.flashes++
+	id, status := u.flashes, u.status
+	status.SetText(" " + colorTag(theme.Accent) + tview.Escape(message) + "[-]")
+	time.AfterFunc(3*time.Second, func() {
+		u.app.QueueUpdateDraw(func() {
+			if id == u.flashes {
+				status.SetText(u.statusText())
+			}
+		})
+	})
+}
+
+// statusText is the status line for the notes shown, naming the author
+// they are narrowed down to, if any.
+func (u *UI) statusText() string {
+	status := renderStatus(u.data, u.shownSources())
+	if u.author != "" {
+		status += " · by " + tview.Escape(u.author)
+	}
+	return status
+}
+
+// renderStatus summarises what was loaded: where from, how much, when, and
+// how many problems the parser ran into (details are in trail.log).
+func renderStatus(data *TrailData, sources []noteSource) string {
+	home, _ := os.UserHomeDir()
+	shown := make([]string, len(sources))
+	for i, source := range sources {
+		dir := source.name
+		if home != "" && (dir == home || strings.HasPrefix(dir, home+string(filepath.Separator))) {
+			dir = "~" + dir[len(home):]
+		}
+		shown[i] = dir
+	}
+
+	entries := 0
+	for _, project := range data.Projects {
+		for _, taskEntries := range project.Tasks {
+			entries += len(taskEntries)
+		}
+	}
+
+	status := fmt.Sprintf(" %s · %d %s · %d %s · %d %s · loaded %s",
+		tview.Escape(strings.Join(shown, ", ")),
+		len(data.Files), plural(len(data.Files), "file", "files"),
+		len(data.Projects), plural(len(data.Projects), "project", "projects"),
+		entries, plural(entries, "entry", "entries"),
+		data.LoadedAt.Format("15:04:05"))
+	if n := len(data.Diagnostics); n > 0 {
+		status += fmt.Sprintf(" · %s%d %s[-]", colorTag(theme.Todo), n, plural(n, "warning", "warnings"))
+	}
+	return status
+}
+
+// --- Help overlay ---
+
+// showHelp lists the bindings that work on the current screen.
+func (u *UI) showHelp() {
+	keyWidth, helpWidth := 0, 0
+	for _, a := range actions {
+		keyWidth = max(keyWidth, len([]rune(u.cfg.keys.describe(a.Name))))
+		helpWidth = max(helpWidth, len([]rune(a.Help)))
+	}
+	var global, local strings.Builder
+	for _, a := range actions {
+		keys := u.cfg.keys.describe(a.Name)
+		if keys == "" || !a.appliesTo(u.current) {
+			continue
+		}
+		sb := &global
+		if a.Screens != nil {
+			sb = &local
+		}
+		fmt.Fprintf(sb, "  %s%-*s[-]  %s\n", colorTag(theme.Accent), keyWidth, tview.Escape(keys), a.Help)
+	}
+	text := "Everywhere\n" + global.String()
+	if local.Len() > 0 {
+		text += "\nOn " + u.current + "\n" + local.String()
+	}
+	text = strings.TrimRight(text, "\n")
+
+	view := tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetText(text)
+	view.SetBorder(true).SetTitle(" Keys ")
+	width := keyWidth + helpWidth + 6 // indent, gap and borders
+	height := strings.Count(text, "\n") + 3
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(view, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	u.helpReturn = u.app.GetFocus()
+	u.root.AddPage("help", modal, true, true)
+	u.app.SetFocus(view)
+}
+
+func (u *UI) hideHelp() {
+	u.root.RemovePage("help")
+	u.app.SetFocus(u.helpReturn)
+}
+
+// --- Keys ---
+
+func (u *UI) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	// Single-character bindings are left alone while typing in an input.
+	_, typing := u.app.GetFocus().(*tview.InputField)
+	action, wait := u.cfg.keys.resolve(event, u.current, typing)
+	if wait {
+		return nil
+	}
+
+	// Movement is handed on as the equivalent plain key, which every list,
+	// text view and custom view understands.
+	if key, ok := motionKeys[action]; ok && !typing {
+		return tcell.NewEventKey(key, 0, tcell.ModNone)
+	}
+
+	if u.root.HasPage("help") {
+		switch action {
+		case "quit":
+			u.app.Stop()
+		case "back", "help":
+			u.hideHelp()
+		}
+		return nil
+	}
+
+	switch action {
+	case "quit":
+		u.app.Stop()
+	case "next_screen":
+		switchScreen(u.pages, &u.current, 1)
+	case "prev_screen":
+		switchScreen(u.pages, &u.current, -1)
+	case "back":
+		switch u.current {
+		case "projects":
+			u.projects.handleEsc()
+		case "tasks":
+			u.tasks.handleEsc()
+		case "days":
+			u.days.handleEsc()
+		case "recent":
+			u.recent.handleEsc()
+		case "stats":
+			u.stats.handleEsc()
+		case "authors":
+			u.authors.handleEsc()
+		}
+	case "edit":
+		u.editToday()
+	case "yank":
+		u.yank()
+	case "pin":
+		u.togglePin()
+	case "help":
+		u.showHelp()
+	case "split":
+		splitLayout = !splitLayout
+		for _, sv := range []*splitView{u.projects.split, u.tasks.split, u.days.split, u.authors.split} {
+			if sv.preview.HasFocus() {
+				u.app.SetFocus(sv.pages)
+			}
+		}
+	case "theme":
+		u.cycleTheme()
+	case "source":
+		u.cycleSource()
+	case "author":
+		u.cycleAuthor()
+	case "filter":
+		switch u.current {
+		case "projects":
+			u.projects.focusFilter()
+		case "tasks":
+			u.tasks.focusFilter()
+		case "days":
+			u.days.focusFilter()
+		case "recent":
+			u.recent.focusFilter()
+		case "stats":
+			u.stats.focusFilter()
+		case "authors":
+			u.authors.focusFilter()
+		}
+	case "prev_week":
+		u.week.shiftWeek(-1)
+	case "next_week":
+		u.week.shiftWeek(1)
+	case "today":
+		switch u.current {
+		case "week":
+			u.week.showWeek(currentDay())
+		case "calendar":
+			u.calendar.heatmap.jumpTo(u.calendar.heatmap.today)
+		case "timeline":
+			u.timeline.chart.showToday()
+		}
+	case "first":
+		u.timeline.chart.showFirst()
+	case "last":
+		u.timeline.chart.showLast()
+	case "expand":
+		switch u.current {
+		case "recent":
+			u.recent.content.toggle()
+		case "timeline":
+			u.timeline.chart.toggle()
+		}
+	case "prev_project":
+		u.recent.content.jumpProject(-1)
+	case "next_project":
+		u.recent.content.jumpProject(1)
+	default:
+		return event
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// --- WeekScreen ---
+
+type WeekScreen struct {
+	Root    *tview.Grid
+	header  *tview.TextView
+	labels  [7]*tview.TextView
+	columns [7]*tview.TextView
+	data    *TrailData
+	counts  map[time.Time]int
+	app     *tview.Application
+	monday  time.Time
+}
+
+func newWeekScreen(data *TrailData, app *tview.Application) *WeekScreen {
+	ws := &WeekScreen{data: data, counts: entryCountsByDate(data), app: app}
+
+	ws.header = defaultText("").SetDynamicColors(true)
+	ws.Root = tview.NewGrid().SetRows(1, 1, 0).SetColumns(0, 0, 0, 0, 0, 0, 0).SetBorders(true)
+	ws.Root.AddItem(ws.header, 0, 0, 1, 7, 0, 0, false)
+	for i := range ws.columns {
+		ws.labels[i] = defaultText("").SetDynamicColors(true)
+		ws.columns[i] = tview.NewTextView().SetScrollable(true).SetWrap(true)
+		ws.Root.AddItem(ws.labels[i], 1, i, 1, 1, 0, 0, false)
+		ws.Root.AddItem(ws.columns[i], 2, i, 1, 1, 0, 0, i == 0)
+	}
+
+	ws.Root.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyLeft:
+			ws.shiftWeek(-1)
+			return nil
+		case tcell.KeyRight:
+			ws.shiftWeek(1)
+			return nil
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd:
+			ws.scrollColumns(event)
+			return nil
+		}
+		return event
+	})
+
+	// The scroll wheel moves every column together, like j/k.
+	ws.Root.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		switch action {
+		case tview.MouseScrollUp:
+			ws.scrollColumns(tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
+			return tview.MouseConsumed, nil
+		case tview.MouseScrollDown:
+			ws.scrollColumns(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+			return tview.MouseConsumed, nil
+		}
+		return action, event
+	})
+
+	ws.showWeek(currentDay())
+	return ws
+}
+
+// showWeek lays out the ISO week (Monday to Sunday) containing date.
+func (ws *WeekScreen) showWeek(date time.Time) {
+	ws.monday = date.AddDate(0, 0, -weekdayIndex(date))
+	today := currentDay()
+
+	year, week := ws.monday.ISOWeek()
+	sunday := ws.monday.AddDate(0, 0, 6)
+	ws.header.SetText(fmt.Sprintf("%s%d-W%02d[-]  %s – %s", colorTag(theme.Accent), year, week,
+		ws.monday.Format(longDate), sunday.Format(longDate)))
+
+	for i := range ws.columns {
+		day := ws.monday.AddDate(0, 0, i)
+		label := day.Format("Mon 01-02")
+		if day.Equal(today) {
+			label = colorTag(theme.Highlight) + label + "[-]"
+		}
+		ws.labels[i].SetText(label)
+		if ws.counts[day] > 0 {
+			ws.columns[i].SetText(renderDaySummary(day, ws.data))
+		} else {
+			ws.columns[i].SetText("")
+		}
+		ws.columns[i].ScrollToBeginning()
+	}
+}
+
+func (ws *WeekScreen) shiftWeek(weeks int) {
+	ws.showWeek(ws.monday.AddDate(0, 0, 7*weeks))
+}
+
+// refresh re-reads the notes for the week that is showing.
+func (ws *WeekScreen) refresh() {
+	ws.counts = entryCountsByDate(ws.data)
+	ws.showWeek(ws.monday)
+}
+
+func (ws *WeekScreen) restyle() {
+	restyle(ws.Root, ws.header)
+	for i := range ws.columns {
+		restyle(ws.labels[i], ws.columns[i])
+	}
+}
+
+// yankText is every day of the week that has entries.
+func (ws *WeekScreen) yankText() (text, what string) {
+	var days []string
+	for i := range 7 {
+		if day := ws.monday.AddDate(0, 0, i); ws.counts[day] > 0 {
+			days = append(days, markdownDay(day, ws.data))
+		}
+	}
+	year, week := ws.monday.ISOWeek()
+	return strings.Join(days, "\n"), fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// scrollColumns forwards a scroll key to every day column so they move
+// together.
+func (ws *WeekScreen) scrollColumns(event *tcell.EventKey) {
+	for _, column := range ws.columns {
+		column.InputHandler()(event, func(tview.Primitive) {})
+	}
+}